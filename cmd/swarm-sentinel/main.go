@@ -51,7 +51,12 @@ func main() {
 	logger := logging.NewWithLevel(cfg.LogLevel)
 
 	logger.Info().
-		Str("compose_url", cfg.ComposeURL).
+		Str("compose_url", compose.RedactURL(cfg.ComposeURL)).
+		Str("compose_auth", authType(cfg.ComposeAuth)).
+		Bool("compose_tls_client_cert", cfg.ComposeTLS.CertFile != "").
+		Bool("compose_signature_verification", cfg.ComposeSignature != nil).
+		Str("compose_tls_ca", cfg.ComposeTLS.CAFile).
+		Str("compose_env_file", compose.RedactURL(cfg.ComposeEnvFile)).
 		Dur("compose_timeout", cfg.ComposeTimeout).
 		Str("docker_proxy_url", cfg.DockerProxyURL).
 		Dur("docker_api_timeout", cfg.DockerAPITimeout).
@@ -59,10 +64,16 @@ func main() {
 		Bool("docker_tls_enabled", cfg.DockerTLSEnabled).
 		Dur("poll_interval", cfg.PollInterval).
 		Int("alert_stabilization_cycles", cfg.AlertStabilizationCycles).
+		Int("source_failure_threshold", cfg.SourceFailureThreshold).
+		Dur("source_max_age", cfg.SourceMaxAge).
+		Strs("label_prefixes", cfg.LabelPrefixes).
+		Str("policy_drift_severity", cfg.PolicyDriftSeverity).
 		Str("log_level", cfg.LogLevel).
 		Str("state_path", cfg.StatePath).
 		Str("slack_webhook", secretStatus(cfg.SlackWebhookURL)).
 		Str("webhook_url", secretStatus(cfg.WebhookURL)).
+		Str("s3_endpoint", cfg.S3Endpoint).
+		Str("s3_credentials", secretStatus(cfg.S3AccessKeyID)).
 		Int("health_port", cfg.HealthPort).
 		Int("metrics_port", cfg.MetricsPort).
 		Bool("dry_run", cfg.DryRun).
@@ -95,6 +106,7 @@ func main() {
 
 	stateStore := state.NewFileStore(cfg.StatePath, logger)
 	stateMu := &sync.Mutex{}
+	desiredCache := state.NewFileDesiredCache(state.DesiredCacheDir(cfg.StatePath), logger)
 
 	var tracker *healthcheck.Tracker
 	if cfg.HealthPort != 0 {
@@ -143,6 +155,7 @@ func main() {
 			mappings,
 			swarmClient,
 			coordinator.WithStateStore(stateStore, stateMu),
+			coordinator.WithDesiredStateCache(desiredCache),
 			coordinator.WithNotifier(notifier),
 			coordinator.WithAlertStabilizationCycles(cfg.AlertStabilizationCycles),
 			coordinator.WithCycleTracker(tracker),
@@ -158,26 +171,56 @@ func main() {
 		}
 
 		logger.Info().
-			Str("compose_url", cfg.ComposeURL).
+			Str("compose_url", compose.RedactURL(cfg.ComposeURL)).
 			Str("stack_name", cfg.StackName).
 			Msg("single-stack mode")
 
-		composeFetcher, err := compose.NewHTTPFetcher(cfg.ComposeURL, cfg.ComposeTimeout, 0)
+		mapping := config.StackMapping{
+			Name:       cfg.StackName,
+			ComposeURL: cfg.ComposeURL,
+			Auth:       cfg.ComposeAuth,
+		}
+		composeFetcher, err := compose.NewFetcher(cfg.ComposeURL, cfg.ComposeTimeout, 0, coordinator.SourceOptions(cfg, mapping)...)
 		if err != nil {
 			logger.Fatal().Err(err).Msg("failed to initialize compose fetcher")
 		}
+		if cfg.ComposeSignature != nil {
+			mapping.Signature = &config.SignatureConfig{URL: cfg.ComposeSignature.URL}
+		}
+		verifier, err := coordinator.ComposeVerifier(cfg, mapping, cfg.ComposeTimeout)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("failed to initialize compose signature verification")
+		}
+		mapping.EnvFile = cfg.ComposeEnvFile
+		envFetcher, err := coordinator.ComposeEnvFetcher(cfg, mapping, cfg.ComposeTimeout)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("failed to initialize compose env file")
+		}
 
-		r := runner.New(
-			logger,
-			cfg.PollInterval,
+		runnerOpts := []runner.Option{
 			runner.WithComposeFetcher(composeFetcher),
 			runner.WithSwarmClient(swarmClient),
 			runner.WithStackName(cfg.StackName),
 			runner.WithStateStore(stateStore, stateMu),
+			runner.WithDesiredStateCache(desiredCache),
 			runner.WithNotifier(notifier),
 			runner.WithAlertStabilizationCycles(cfg.AlertStabilizationCycles),
+			runner.WithSourceAlerts(cfg.SourceFailureThreshold, cfg.SourceMaxAge),
+			runner.WithHealthOptions(coordinator.HealthOptions(cfg)...),
 			runner.WithCycleTracker(tracker),
 			runner.WithMetrics(metricsCollector),
+		}
+		if verifier != nil {
+			runnerOpts = append(runnerOpts, runner.WithComposeVerifier(verifier))
+		}
+		if envFetcher != nil {
+			runnerOpts = append(runnerOpts, runner.WithComposeEnv(envFetcher))
+		}
+
+		r := runner.New(
+			logger,
+			cfg.PollInterval,
+			runnerOpts...,
 		)
 		if err := r.Run(ctx); err != nil {
 			logger.Fatal().Err(err).Msg("runner exited with error")
@@ -192,6 +235,13 @@ func secretStatus(value string) string {
 	return "set"
 }
 
+func authType(auth *config.AuthConfig) string {
+	if auth == nil {
+		return "none"
+	}
+	return auth.Type
+}
+
 // runHealthcheck performs an HTTP health check against the local healthz endpoint.
 // This is used for container healthchecks in distroless images where curl/wget aren't available.
 func runHealthcheck() int {
@@ -0,0 +1,158 @@
+package compose
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Supported HTTPAuth types.
+const (
+	AuthBearer = "bearer"
+	AuthBasic  = "basic"
+	AuthHeader = "header"
+)
+
+// HTTPAuth describes credentials applied to compose HTTP requests.
+//   - bearer: sends "Authorization: Bearer <Token>" (e.g., GitHub raw)
+//   - basic: sends HTTP basic auth with Username and Password
+//   - header: sends "<Header>: <Token>" (e.g., GitLab PRIVATE-TOKEN or JOB-TOKEN)
+type HTTPAuth struct {
+	Type     string
+	Username string
+	Password string
+	Header   string
+	Token    string
+}
+
+// Validate reports whether the auth settings are complete for their type.
+func (a HTTPAuth) Validate() error {
+	switch a.Type {
+	case AuthBearer:
+		if a.Token == "" {
+			return errors.New("bearer auth requires a token")
+		}
+	case AuthBasic:
+		if a.Username == "" {
+			return errors.New("basic auth requires a username")
+		}
+	case AuthHeader:
+		if a.Header == "" {
+			return errors.New("header auth requires a header name")
+		}
+		if a.Token == "" {
+			return errors.New("header auth requires a token")
+		}
+	default:
+		return fmt.Errorf("unsupported auth type %q", a.Type)
+	}
+	return nil
+}
+
+// apply sets the credentials on the request.
+func (a HTTPAuth) apply(req *http.Request) error {
+	switch a.Type {
+	case AuthBearer:
+		req.Header.Set("Authorization", "Bearer "+a.Token)
+	case AuthBasic:
+		req.SetBasicAuth(a.Username, a.Password)
+	case AuthHeader:
+		req.Header.Set(a.Header, a.Token)
+	default:
+		return fmt.Errorf("unsupported auth type %q", a.Type)
+	}
+	return nil
+}
+
+// headerName returns the request header carrying the credentials.
+func (a HTTPAuth) headerName() string {
+	if a.Type == AuthHeader {
+		return http.CanonicalHeaderKey(a.Header)
+	}
+	return "Authorization"
+}
+
+// WithAuth authenticates every compose request with the given credentials.
+// Credentials are dropped if the server redirects to a different host.
+func WithAuth(auth HTTPAuth) HTTPFetcherOption {
+	return func(f *HTTPFetcher) {
+		f.authHeader = auth.headerName()
+		f.prepare = append(f.prepare, auth.apply)
+	}
+}
+
+// WithHeaders adds static headers to every compose request.
+func WithHeaders(headers map[string]string) HTTPFetcherOption {
+	return func(f *HTTPFetcher) {
+		if len(headers) == 0 {
+			return
+		}
+		copied := make(map[string]string, len(headers))
+		for name, value := range headers {
+			copied[name] = value
+		}
+		f.prepare = append(f.prepare, func(req *http.Request) error {
+			for name, value := range copied {
+				req.Header.Set(name, value)
+			}
+			return nil
+		})
+	}
+}
+
+// sensitiveQueryKeys lists query parameters whose values are redacted in logs.
+var sensitiveQueryKeys = []string{
+	"token",
+	"private_token",
+	"job_token",
+	"access_token",
+	"password",
+	"secret",
+	"signature",
+	"sig",
+	"x-amz-signature",
+	"x-amz-credential",
+	"x-amz-security-token",
+}
+
+// RedactURL returns the URL with embedded credentials masked so it can be
+// logged safely. User info and well-known credential query parameters are
+// replaced with "xxxxx". Unparseable input is fully redacted.
+func RedactURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return "[redacted]"
+	}
+	if parsed.User != nil {
+		parsed.User = url.User("xxxxx")
+	}
+	if parsed.RawQuery != "" {
+		query := parsed.Query()
+		changed := false
+		for key := range query {
+			if isSensitiveQueryKey(key) {
+				query.Set(key, "xxxxx")
+				changed = true
+			}
+		}
+		if changed {
+			parsed.RawQuery = query.Encode()
+		}
+	}
+	return parsed.String()
+}
+
+func isSensitiveQueryKey(key string) bool {
+	lower := strings.ToLower(key)
+	for _, sensitive := range sensitiveQueryKeys {
+		if lower == sensitive {
+			return true
+		}
+	}
+	return false
+}
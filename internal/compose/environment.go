@@ -0,0 +1,87 @@
+package compose
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// sentinelExtension is the service-level compose extension holding
+// swarm-sentinel settings, e.g.
+//
+//	services:
+//	  web:
+//	    x-swarm-sentinel:
+//	      ignore_env: [BUILD_ID, OTEL_*]
+const sentinelExtension = "x-swarm-sentinel"
+
+// sentinelSettings returns a service's x-swarm-sentinel mapping, or nil when
+// the service has none.
+func sentinelSettings(extensions types.Extensions) (map[string]any, error) {
+	raw, ok := extensions[sentinelExtension]
+	if !ok {
+		return nil, nil
+	}
+	settings, ok := raw.(map[string]any)
+	if !ok {
+		return nil, errors.New("must be a mapping")
+	}
+	return settings, nil
+}
+
+// HashEnvValue returns the digest environment values are compared by, so
+// values never need to be kept or printed. swarm.ActualService environments
+// are hashed with it too.
+func HashEnvValue(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// resolveEnvironment hashes a service's environment. Variables declared
+// without a value are passed to Swarm as a bare name and are recorded with an
+// empty hash, which is also how a bare name in the service spec is read.
+func resolveEnvironment(env types.MappingWithEquals) map[string]string {
+	if len(env) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(env))
+	for name, value := range env {
+		if value == nil {
+			result[name] = ""
+			continue
+		}
+		result[name] = HashEnvValue(*value)
+	}
+	return result
+}
+
+// resolveIgnoreEnv reads x-swarm-sentinel.ignore_env from a service. Entries
+// are variable names, or prefixes when they end in "*".
+func resolveIgnoreEnv(extensions types.Extensions) ([]string, error) {
+	settings, err := sentinelSettings(extensions)
+	if err != nil {
+		return nil, err
+	}
+	rawList, ok := settings["ignore_env"]
+	if !ok {
+		return nil, nil
+	}
+	list, ok := rawList.([]any)
+	if !ok {
+		return nil, errors.New("ignore_env must be a list of variable names")
+	}
+	names := make([]string, 0, len(list))
+	for _, item := range list {
+		name, ok := item.(string)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("ignore_env entry %v is not a variable name", item)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
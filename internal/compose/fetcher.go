@@ -23,11 +23,31 @@ type Fetcher interface {
 }
 
 // FetchResult contains the fetched compose bytes and response metadata.
+// Revision is set by sources that can attribute the content to a commit.
 type FetchResult struct {
 	Body         []byte
 	ETag         string
 	LastModified string
 	NotModified  bool
+	Revision     *Revision
+}
+
+// ParseLastModified parses FetchResult.LastModified. HTTP and file sources
+// use the HTTP date format; git sources report the RFC 2822 commit date.
+func ParseLastModified(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+	if parsed, err := http.ParseTime(value); err == nil {
+		return parsed, true
+	}
+	for _, layout := range []string{"Mon, 2 Jan 2006 15:04:05 -0700", time.RFC3339} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
 }
 
 // FetchError provides detailed error information for fetch failures.
@@ -40,7 +60,7 @@ type FetchError struct {
 
 func (e *FetchError) Error() string {
 	if e.StatusCode > 0 {
-		return fmt.Sprintf("compose fetch failed: HTTP %d from %s", e.StatusCode, e.URL)
+		return fmt.Sprintf("compose fetch failed: HTTP %d from %s", e.StatusCode, RedactURL(e.URL))
 	}
 	return fmt.Sprintf("compose fetch failed: %v", e.Err)
 }
@@ -66,6 +86,8 @@ type HTTPFetcher struct {
 	maxBytes   int64
 	maxRetries int
 	retryDelay time.Duration
+	prepare    []func(*http.Request) error
+	authHeader string
 }
 
 // HTTPFetcherOption configures an HTTPFetcher.
@@ -92,6 +114,16 @@ func WithRetryDelay(d time.Duration) HTTPFetcherOption {
 	}
 }
 
+// withRequestPreparer registers a hook that runs on every outgoing request
+// just before it is sent, e.g. to sign it.
+func withRequestPreparer(fn func(*http.Request) error) HTTPFetcherOption {
+	return func(f *HTTPFetcher) {
+		if fn != nil {
+			f.prepare = append(f.prepare, fn)
+		}
+	}
+}
+
 // NewHTTPFetcher constructs an HTTPFetcher with the given URL and timeout.
 func NewHTTPFetcher(composeURL string, timeout time.Duration, maxBytes int64, opts ...HTTPFetcherOption) (*HTTPFetcher, error) {
 	if strings.TrimSpace(composeURL) == "" {
@@ -121,9 +153,28 @@ func NewHTTPFetcher(composeURL string, timeout time.Duration, maxBytes int64, op
 		opt(f)
 	}
 
+	if f.authHeader != "" {
+		f.client.CheckRedirect = dropAuthOnHostChange(f.authHeader)
+	}
+
 	return f, nil
 }
 
+// dropAuthOnHostChange strips the credential header when a redirect leaves
+// the original host. net/http already does this for Authorization, but not
+// for custom headers such as PRIVATE-TOKEN.
+func dropAuthOnHostChange(header string) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		if req.URL.Host != via[0].URL.Host {
+			req.Header.Del(header)
+		}
+		return nil
+	}
+}
+
 // Fetch downloads the compose file, optionally using ETag caching.
 // Transient network errors are retried with exponential backoff.
 func (f *HTTPFetcher) Fetch(ctx context.Context, previousETag string) (FetchResult, error) {
@@ -226,6 +277,11 @@ func (f *HTTPFetcher) doFetch(ctx context.Context, previousETag string) (FetchRe
 	if previousETag != "" {
 		req.Header.Set("If-None-Match", previousETag)
 	}
+	for _, prepare := range f.prepare {
+		if err := prepare(req); err != nil {
+			return FetchResult{}, &nonRetryableError{&FetchError{URL: f.url, Err: fmt.Errorf("prepare request: %w", err)}}
+		}
+	}
 
 	resp, err := f.client.Do(req)
 	if err != nil {
@@ -311,3 +311,21 @@ type testError struct {
 func (e *testError) Error() string {
 	return e.msg
 }
+
+func TestParseLastModified(t *testing.T) {
+	want := time.Date(2024, 3, 5, 14, 30, 0, 0, time.UTC)
+	tests := []string{
+		"Tue, 05 Mar 2024 14:30:00 GMT",
+		"Tue, 5 Mar 2024 15:30:00 +0100",
+		"2024-03-05T14:30:00Z",
+	}
+	for _, value := range tests {
+		got, ok := ParseLastModified(value)
+		if !ok || !got.Equal(want) {
+			t.Fatalf("ParseLastModified(%q) = %v, %v", value, got, ok)
+		}
+	}
+	if _, ok := ParseLastModified("yesterday"); ok {
+		t.Fatal("expected unparseable value to be rejected")
+	}
+}
@@ -0,0 +1,148 @@
+package compose
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// defaultComposeFilenames lists the files probed, in order, when a file source
+// points at a directory. This mirrors the lookup order used by docker compose.
+var defaultComposeFilenames = []string{
+	"compose.yaml",
+	"compose.yml",
+	"docker-compose.yaml",
+	"docker-compose.yml",
+}
+
+// FileFetcher reads a compose file from the local filesystem.
+// The file's modification time and size stand in for an HTTP ETag, so an
+// unchanged file reports NotModified without being read.
+type FileFetcher struct {
+	path     string
+	maxBytes int64
+}
+
+// NewFileFetcher constructs a FileFetcher for a file:// URL.
+// The URL may reference a compose file or a directory containing one of the
+// standard compose filenames (compose.yaml, compose.yml, docker-compose.yaml,
+// docker-compose.yml).
+func NewFileFetcher(composeURL string, maxBytes int64) (*FileFetcher, error) {
+	path, err := filePathFromURL(composeURL)
+	if err != nil {
+		return nil, err
+	}
+	if maxBytes <= 0 {
+		maxBytes = defaultMaxBytes
+	}
+
+	return &FileFetcher{
+		path:     path,
+		maxBytes: maxBytes,
+	}, nil
+}
+
+// Fetch reads the compose file unless its mtime/size match previousETag.
+func (f *FileFetcher) Fetch(ctx context.Context, previousETag string) (FetchResult, error) {
+	if err := ctx.Err(); err != nil {
+		return FetchResult{}, err
+	}
+
+	path, info, err := f.resolve()
+	if err != nil {
+		return FetchResult{}, err
+	}
+
+	etag := fileETag(info)
+	lastModified := info.ModTime().UTC().Format(http.TimeFormat)
+	if previousETag != "" && previousETag == etag {
+		return FetchResult{
+			ETag:         etag,
+			LastModified: lastModified,
+			NotModified:  true,
+		}, nil
+	}
+
+	if info.Size() > f.maxBytes {
+		return FetchResult{}, &FetchError{URL: path, Err: fmt.Errorf("compose body exceeds %d bytes", f.maxBytes)}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return FetchResult{}, &FetchError{URL: path, Err: fmt.Errorf("open compose: %w", err)}
+	}
+	defer file.Close()
+
+	body, err := readWithLimit(file, f.maxBytes)
+	if err != nil {
+		return FetchResult{}, &FetchError{URL: path, Err: err}
+	}
+	if len(body) == 0 {
+		return FetchResult{}, &FetchError{URL: path, Err: errors.New("compose body is empty")}
+	}
+
+	return FetchResult{
+		Body:         body,
+		ETag:         etag,
+		LastModified: lastModified,
+	}, nil
+}
+
+// resolve returns the compose file path and its metadata, probing the
+// standard compose filenames when the configured path is a directory.
+func (f *FileFetcher) resolve() (string, os.FileInfo, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return "", nil, &FetchError{URL: f.path, Err: fmt.Errorf("stat compose: %w", err)}
+	}
+	if !info.IsDir() {
+		return f.path, info, nil
+	}
+
+	for _, name := range defaultComposeFilenames {
+		candidate := filepath.Join(f.path, name)
+		candidateInfo, err := os.Stat(candidate)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return "", nil, &FetchError{URL: candidate, Err: fmt.Errorf("stat compose: %w", err)}
+		}
+		if candidateInfo.IsDir() {
+			continue
+		}
+		return candidate, candidateInfo, nil
+	}
+
+	return "", nil, &FetchError{URL: f.path, Err: fmt.Errorf("no compose file found in directory %s", f.path)}
+}
+
+// fileETag derives a weak validator from modification time and size.
+func fileETag(info os.FileInfo) string {
+	return fmt.Sprintf("W/\"%x-%x\"", info.ModTime().UnixNano(), info.Size())
+}
+
+func filePathFromURL(composeURL string) (string, error) {
+	if strings.TrimSpace(composeURL) == "" {
+		return "", errors.New("compose url must not be empty")
+	}
+	parsed, err := url.Parse(composeURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid compose url: %w", err)
+	}
+	if parsed.Scheme != "file" {
+		return "", errors.New("compose url must use file scheme")
+	}
+	if parsed.Host != "" && parsed.Host != "localhost" {
+		return "", fmt.Errorf("file compose url must not specify a remote host: %s", parsed.Host)
+	}
+	if parsed.Path == "" || !filepath.IsAbs(filepath.FromSlash(parsed.Path)) {
+		return "", errors.New("file compose url must use an absolute path")
+	}
+	return filepath.Clean(filepath.FromSlash(parsed.Path)), nil
+}
@@ -0,0 +1,196 @@
+package compose
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileFetcher_Fetch_OK(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "stack.yml")
+	if err := os.WriteFile(path, []byte("compose: true\n"), 0o600); err != nil {
+		t.Fatalf("write compose: %v", err)
+	}
+
+	fetcher, err := NewFileFetcher("file://"+path, 1024)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := fetcher.Fetch(context.Background(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.NotModified {
+		t.Fatalf("expected fresh response")
+	}
+	if string(result.Body) != "compose: true\n" {
+		t.Fatalf("unexpected body: %q", string(result.Body))
+	}
+	if result.ETag == "" {
+		t.Fatalf("expected etag to be set")
+	}
+	if result.LastModified == "" {
+		t.Fatalf("expected last-modified to be set")
+	}
+}
+
+func TestFileFetcher_Fetch_NotModifiedUntilChanged(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "stack.yml")
+	if err := os.WriteFile(path, []byte("compose: true\n"), 0o600); err != nil {
+		t.Fatalf("write compose: %v", err)
+	}
+
+	fetcher, err := NewFileFetcher("file://"+path, 1024)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	first, err := fetcher.Fetch(context.Background(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	second, err := fetcher.Fetch(context.Background(), first.ETag)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !second.NotModified {
+		t.Fatalf("expected not modified response")
+	}
+	if len(second.Body) != 0 {
+		t.Fatalf("expected empty body")
+	}
+
+	if err := os.WriteFile(path, []byte("compose: changed\n"), 0o600); err != nil {
+		t.Fatalf("rewrite compose: %v", err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	third, err := fetcher.Fetch(context.Background(), first.ETag)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if third.NotModified {
+		t.Fatalf("expected changed file to be re-read")
+	}
+	if string(third.Body) != "compose: changed\n" {
+		t.Fatalf("unexpected body: %q", string(third.Body))
+	}
+}
+
+func TestFileFetcher_Fetch_Directory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte("legacy: true\n"), 0o600); err != nil {
+		t.Fatalf("write compose: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "compose.yaml"), []byte("preferred: true\n"), 0o600); err != nil {
+		t.Fatalf("write compose: %v", err)
+	}
+
+	fetcher, err := NewFileFetcher("file://"+dir, 1024)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := fetcher.Fetch(context.Background(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(result.Body) != "preferred: true\n" {
+		t.Fatalf("unexpected body: %q", string(result.Body))
+	}
+}
+
+func TestFileFetcher_Fetch_Errors(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.yml")
+	if err := os.WriteFile(empty, nil, 0o600); err != nil {
+		t.Fatalf("write compose: %v", err)
+	}
+	large := filepath.Join(dir, "large.yml")
+	if err := os.WriteFile(large, []byte(strings.Repeat("a", 32)), 0o600); err != nil {
+		t.Fatalf("write compose: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{name: "missing file", path: filepath.Join(dir, "missing.yml"), wantErr: "stat compose"},
+		{name: "empty file", path: empty, wantErr: "compose body is empty"},
+		{name: "oversize file", path: large, wantErr: "exceeds 16 bytes"},
+		{name: "directory without compose", path: t.TempDir(), wantErr: "no compose file found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher, err := NewFileFetcher("file://"+tt.path, 16)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			_, err = fetcher.Fetch(context.Background(), "")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+			var fetchErr *FetchError
+			if !errors.As(err, &fetchErr) {
+				t.Fatalf("expected FetchError, got %T", err)
+			}
+		})
+	}
+}
+
+func TestNewFileFetcher_RejectsInvalidURL(t *testing.T) {
+	tests := []string{
+		"",
+		"https://example.com/compose.yml",
+		"file://remote-host/compose.yml",
+		"file:relative/compose.yml",
+	}
+	for _, composeURL := range tests {
+		if _, err := NewFileFetcher(composeURL, 0); err == nil {
+			t.Fatalf("expected error for %q", composeURL)
+		}
+	}
+}
+
+func TestNewFetcher_SelectsByScheme(t *testing.T) {
+	httpFetcher, err := NewFetcher("https://example.com/compose.yml", time.Second, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := httpFetcher.(*HTTPFetcher); !ok {
+		t.Fatalf("expected *HTTPFetcher, got %T", httpFetcher)
+	}
+
+	fileFetcher, err := NewFetcher("file:///srv/compose.yml", time.Second, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := fileFetcher.(*FileFetcher); !ok {
+		t.Fatalf("expected *FileFetcher, got %T", fileFetcher)
+	}
+
+	gitFetcher, err := NewFetcher("git+file:///srv/artifacts?ref=main&path=prod/compose.yml", time.Second, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := gitFetcher.(*GitFetcher); !ok {
+		t.Fatalf("expected *GitFetcher, got %T", gitFetcher)
+	}
+
+	if _, err := NewFetcher("ftp://example.com/compose.yml", time.Second, 0); err == nil {
+		t.Fatal("expected error for unsupported scheme")
+	}
+}
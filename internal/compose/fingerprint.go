@@ -4,9 +4,14 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
+	"fmt"
+
+	"github.com/compose-spec/compose-go/v2/types"
 )
 
-// Fingerprint computes a SHA-256 hash for the given compose bytes.
+// Fingerprint computes a SHA-256 hash for the given compose bytes. It changes
+// with any edit, including formatting, and is kept for traceability; see
+// DesiredState.Fingerprint for change detection.
 func Fingerprint(body []byte) (string, error) {
 	if len(body) == 0 {
 		return "", errors.New("compose body is empty")
@@ -14,3 +19,36 @@ func Fingerprint(body []byte) (string, error) {
 	sum := sha256.Sum256(body)
 	return hex.EncodeToString(sum[:]), nil
 }
+
+// CombinedFingerprint fingerprints an ordered set of compose files. A single
+// file yields the same value as Fingerprint.
+func CombinedFingerprint(bodies [][]byte) (string, error) {
+	if len(bodies) == 1 {
+		return Fingerprint(bodies[0])
+	}
+	if len(bodies) == 0 {
+		return "", errors.New("no compose files")
+	}
+	combined := sha256.New()
+	for _, body := range bodies {
+		fingerprint, err := Fingerprint(body)
+		if err != nil {
+			return "", err
+		}
+		combined.Write([]byte(fingerprint))
+		combined.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(combined.Sum(nil)), nil
+}
+
+// semanticFingerprint hashes the canonical JSON form of a loaded compose
+// project. Comments, whitespace, key order and YAML anchors do not survive
+// loading, so re-rendering an unchanged compose file yields the same value.
+func semanticFingerprint(project *types.Project) (string, error) {
+	canonical, err := project.MarshalJSON()
+	if err != nil {
+		return "", fmt.Errorf("canonicalize compose: %w", err)
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
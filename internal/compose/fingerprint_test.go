@@ -1,6 +1,9 @@
 package compose
 
-import "testing"
+import (
+	"context"
+	"testing"
+)
 
 func TestFingerprint_Stable(t *testing.T) {
 	body := []byte("version: '3.9'\nservices:\n  web:\n    image: nginx\n")
@@ -37,3 +40,58 @@ func TestFingerprint_RejectsEmpty(t *testing.T) {
 		t.Fatalf("expected error for empty body")
 	}
 }
+
+func TestDesiredStateFingerprint_IgnoresFormatting(t *testing.T) {
+	original := []byte(`services:
+  web:
+    image: nginx:1.27
+    deploy:
+      replicas: 2
+  api:
+    image: app:v1
+`)
+	reformatted := []byte(`# rendered by CI
+services:
+  api: {image: "app:v1"}
+  web:
+    deploy: {replicas: 2}   # scaled for prod
+    image: 'nginx:1.27'
+`)
+	changed := []byte(`services:
+  web:
+    image: nginx:1.27
+    deploy:
+      replicas: 3
+  api:
+    image: app:v1
+`)
+
+	first, err := ParseDesiredState(context.Background(), original)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := ParseDesiredState(context.Background(), reformatted)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	third, err := ParseDesiredState(context.Background(), changed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if first.Fingerprint == "" {
+		t.Fatal("expected semantic fingerprint to be set")
+	}
+	if first.Fingerprint != second.Fingerprint {
+		t.Fatalf("expected reformatted compose to keep fingerprint, got %s and %s", first.Fingerprint, second.Fingerprint)
+	}
+	if first.Fingerprint == third.Fingerprint {
+		t.Fatal("expected content change to alter fingerprint")
+	}
+
+	rawFirst, _ := Fingerprint(original)
+	rawSecond, _ := Fingerprint(reformatted)
+	if rawFirst == rawSecond {
+		t.Fatal("expected raw fingerprints to differ")
+	}
+}
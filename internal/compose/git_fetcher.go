@@ -0,0 +1,182 @@
+package compose
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultGitRef = "HEAD"
+
+// Revision identifies the source revision a compose file was read from.
+// It is populated by fetchers that can attribute content to a commit.
+type Revision struct {
+	Commit string
+	Author string
+	Ref    string
+}
+
+// GitFetcher reads a compose file from a ref of a local git repository.
+// The resolved commit SHA is used as the ETag, so polling an unchanged ref
+// reports NotModified without reading the file.
+//
+// The repository may be a working clone or a bare repository; it is read
+// as-is and is expected to be kept up to date by an external process.
+// Reading relies on the git binary being available on PATH.
+type GitFetcher struct {
+	repo     string
+	ref      string
+	file     string
+	timeout  time.Duration
+	maxBytes int64
+	gitPath  string
+}
+
+// NewGitFetcher constructs a GitFetcher from a git+file:// URL of the form
+// git+file:///path/to/repo?ref=main&path=stacks/prod/compose.yml.
+// ref defaults to HEAD; path is required.
+func NewGitFetcher(composeURL string, timeout time.Duration, maxBytes int64) (*GitFetcher, error) {
+	repo, ref, file, err := parseGitURL(composeURL)
+	if err != nil {
+		return nil, err
+	}
+	if timeout <= 0 {
+		return nil, errors.New("timeout must be greater than zero")
+	}
+	if maxBytes <= 0 {
+		maxBytes = defaultMaxBytes
+	}
+
+	return &GitFetcher{
+		repo:     repo,
+		ref:      ref,
+		file:     file,
+		timeout:  timeout,
+		maxBytes: maxBytes,
+		gitPath:  "git",
+	}, nil
+}
+
+// Fetch resolves the configured ref and reads the compose file at that commit.
+func (f *GitFetcher) Fetch(ctx context.Context, previousETag string) (FetchResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, f.timeout)
+	defer cancel()
+
+	commit, err := f.git(ctx, "rev-parse", "--verify", "--quiet", f.ref+"^{commit}")
+	if err != nil {
+		return FetchResult{}, f.fetchError(fmt.Errorf("resolve ref %q: %w", f.ref, err))
+	}
+	commit = strings.TrimSpace(commit)
+
+	meta, err := f.git(ctx, "show", "-s", "--format=%an <%ae>%x00%cD", commit)
+	if err != nil {
+		return FetchResult{}, f.fetchError(fmt.Errorf("read commit %s: %w", commit, err))
+	}
+	author, committedAt, _ := strings.Cut(strings.TrimSpace(meta), "\x00")
+	revision := &Revision{
+		Commit: commit,
+		Author: author,
+		Ref:    f.ref,
+	}
+
+	if previousETag != "" && previousETag == commit {
+		return FetchResult{
+			ETag:         commit,
+			LastModified: committedAt,
+			NotModified:  true,
+			Revision:     revision,
+		}, nil
+	}
+
+	object := commit + ":" + f.file
+	size, err := f.git(ctx, "cat-file", "-s", object)
+	if err != nil {
+		return FetchResult{}, f.fetchError(fmt.Errorf("locate %s at %s: %w", f.file, commit, err))
+	}
+	parsedSize, err := strconv.ParseInt(strings.TrimSpace(size), 10, 64)
+	if err != nil {
+		return FetchResult{}, f.fetchError(fmt.Errorf("parse object size: %w", err))
+	}
+	if parsedSize > f.maxBytes {
+		return FetchResult{}, f.fetchError(fmt.Errorf("compose body exceeds %d bytes", f.maxBytes))
+	}
+	if parsedSize == 0 {
+		return FetchResult{}, f.fetchError(errors.New("compose body is empty"))
+	}
+
+	body, err := f.git(ctx, "cat-file", "blob", object)
+	if err != nil {
+		return FetchResult{}, f.fetchError(fmt.Errorf("read %s at %s: %w", f.file, commit, err))
+	}
+
+	return FetchResult{
+		Body:         []byte(body),
+		ETag:         commit,
+		LastModified: committedAt,
+		Revision:     revision,
+	}, nil
+}
+
+func (f *GitFetcher) git(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, f.gitPath, append([]string{"-C", f.repo}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+func (f *GitFetcher) fetchError(err error) error {
+	return &FetchError{URL: f.repo, Err: err}
+}
+
+func parseGitURL(composeURL string) (string, string, string, error) {
+	if strings.TrimSpace(composeURL) == "" {
+		return "", "", "", errors.New("compose url must not be empty")
+	}
+	parsed, err := url.Parse(composeURL)
+	if err != nil {
+		return "", "", "", fmt.Errorf("invalid compose url: %w", err)
+	}
+	if parsed.Scheme != "git+file" {
+		return "", "", "", errors.New("compose url must use git+file scheme")
+	}
+	if parsed.Host != "" && parsed.Host != "localhost" {
+		return "", "", "", fmt.Errorf("git compose url must not specify a remote host: %s", parsed.Host)
+	}
+	if parsed.Path == "" || !filepath.IsAbs(filepath.FromSlash(parsed.Path)) {
+		return "", "", "", errors.New("git compose url must use an absolute repository path")
+	}
+
+	query := parsed.Query()
+	ref := strings.TrimSpace(query.Get("ref"))
+	if ref == "" {
+		ref = defaultGitRef
+	}
+	if strings.HasPrefix(ref, "-") {
+		return "", "", "", fmt.Errorf("invalid git ref %q", ref)
+	}
+
+	file := strings.TrimPrefix(path.Clean("/"+strings.TrimSpace(query.Get("path"))), "/")
+	if file == "" {
+		return "", "", "", errors.New("git compose url requires a path query parameter")
+	}
+
+	return filepath.Clean(filepath.FromSlash(parsed.Path)), ref, file, nil
+}
@@ -0,0 +1,171 @@
+package compose
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+	dir := t.TempDir()
+	runGit(t, dir, "init", "--quiet", "--initial-branch=main")
+	return dir
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Deploy Bot",
+		"GIT_AUTHOR_EMAIL=deploy@example.com",
+		"GIT_COMMITTER_NAME=Deploy Bot",
+		"GIT_COMMITTER_EMAIL=deploy@example.com",
+		"GIT_CONFIG_NOSYSTEM=1",
+		"HOME="+dir,
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func commitFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	runGit(t, dir, "add", name)
+	runGit(t, dir, "commit", "--quiet", "-m", "update "+name)
+	return runGit(t, dir, "rev-parse", "HEAD")
+}
+
+func TestGitFetcher_Fetch_ReadsFileAtRef(t *testing.T) {
+	repo := newTestRepo(t)
+	first := commitFile(t, repo, "prod/compose.yml", "version: one\n")
+	runGit(t, repo, "tag", "v1")
+	commitFile(t, repo, "prod/compose.yml", "version: two\n")
+
+	fetcher, err := NewGitFetcher("git+file://"+repo+"?ref=v1&path=prod/compose.yml", time.Second*5, 1024)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := fetcher.Fetch(context.Background(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(result.Body) != "version: one\n" {
+		t.Fatalf("unexpected body: %q", string(result.Body))
+	}
+	if result.ETag != first {
+		t.Fatalf("expected etag %s, got %s", first, result.ETag)
+	}
+	if result.Revision == nil {
+		t.Fatal("expected revision to be set")
+	}
+	if result.Revision.Commit != first {
+		t.Fatalf("unexpected revision commit: %s", result.Revision.Commit)
+	}
+	if result.Revision.Author != "Deploy Bot <deploy@example.com>" {
+		t.Fatalf("unexpected revision author: %q", result.Revision.Author)
+	}
+	if result.LastModified == "" {
+		t.Fatal("expected last-modified to be set")
+	}
+}
+
+func TestGitFetcher_Fetch_NotModifiedForSameCommit(t *testing.T) {
+	repo := newTestRepo(t)
+	first := commitFile(t, repo, "compose.yml", "version: one\n")
+
+	fetcher, err := NewGitFetcher("git+file://"+repo+"?ref=main&path=compose.yml", time.Second*5, 1024)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := fetcher.Fetch(context.Background(), first)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.NotModified {
+		t.Fatal("expected not modified for unchanged commit")
+	}
+	if result.Revision == nil || result.Revision.Commit != first {
+		t.Fatalf("expected revision for unchanged commit, got %+v", result.Revision)
+	}
+
+	second := commitFile(t, repo, "compose.yml", "version: two\n")
+	result, err = fetcher.Fetch(context.Background(), first)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.NotModified {
+		t.Fatal("expected new commit to be fetched")
+	}
+	if result.ETag != second {
+		t.Fatalf("expected etag %s, got %s", second, result.ETag)
+	}
+}
+
+func TestGitFetcher_Fetch_Errors(t *testing.T) {
+	repo := newTestRepo(t)
+	commitFile(t, repo, "compose.yml", strings.Repeat("a", 32))
+	commitFile(t, repo, "empty.yml", "")
+
+	tests := []struct {
+		name    string
+		url     string
+		wantErr string
+	}{
+		{name: "unknown ref", url: "git+file://" + repo + "?ref=missing&path=compose.yml", wantErr: "resolve ref"},
+		{name: "missing file", url: "git+file://" + repo + "?path=other.yml", wantErr: "locate other.yml"},
+		{name: "oversize file", url: "git+file://" + repo + "?path=compose.yml", wantErr: "exceeds 16 bytes"},
+		{name: "empty file", url: "git+file://" + repo + "?path=empty.yml", wantErr: "compose body is empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher, err := NewGitFetcher(tt.url, time.Second*5, 16)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			_, err = fetcher.Fetch(context.Background(), "")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+			var fetchErr *FetchError
+			if !errors.As(err, &fetchErr) {
+				t.Fatalf("expected FetchError, got %T", err)
+			}
+		})
+	}
+}
+
+func TestNewGitFetcher_RejectsInvalidURL(t *testing.T) {
+	tests := []string{
+		"",
+		"file:///srv/repo?path=compose.yml",
+		"git+file:///srv/repo",
+		"git+file://remote/srv/repo?path=compose.yml",
+		"git+file:///srv/repo?ref=--upload-pack=x&path=compose.yml",
+	}
+	for _, composeURL := range tests {
+		if _, err := NewGitFetcher(composeURL, time.Second, 0); err == nil {
+			t.Fatalf("expected error for %q", composeURL)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package compose
+
+import (
+	"errors"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// healthcheckNone is the test Swarm stores for a disabled healthcheck.
+const healthcheckNone = "NONE"
+
+// Healthcheck holds a service's healthcheck as `docker stack deploy` passes
+// it to Swarm. Test is ["NONE"] when the healthcheck is disabled and empty
+// when the image's test is inherited; zero durations and retries are also
+// inherited from the image.
+type Healthcheck struct {
+	Test        []string
+	Interval    time.Duration
+	Timeout     time.Duration
+	StartPeriod time.Duration
+	Retries     uint64
+}
+
+// resolveHealthcheck returns nil when the service has no healthcheck block.
+func resolveHealthcheck(config *types.HealthCheckConfig) (*Healthcheck, error) {
+	if config == nil {
+		return nil, nil
+	}
+	if config.Disable {
+		if len(config.Test) > 0 && config.Test[0] != healthcheckNone {
+			return nil, errors.New("test and disable can't be set at the same time")
+		}
+		return &Healthcheck{Test: []string{healthcheckNone}}, nil
+	}
+	healthcheck := &Healthcheck{}
+	if len(config.Test) > 0 {
+		healthcheck.Test = append([]string(nil), config.Test...)
+	}
+	if config.Interval != nil {
+		healthcheck.Interval = time.Duration(*config.Interval)
+	}
+	if config.Timeout != nil {
+		healthcheck.Timeout = time.Duration(*config.Timeout)
+	}
+	if config.StartPeriod != nil {
+		healthcheck.StartPeriod = time.Duration(*config.StartPeriod)
+	}
+	if config.Retries != nil {
+		healthcheck.Retries = *config.Retries
+	}
+	return healthcheck, nil
+}
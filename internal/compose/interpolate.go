@@ -0,0 +1,142 @@
+package compose
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/dotenv"
+	"github.com/compose-spec/compose-go/v2/template"
+	"gopkg.in/yaml.v3"
+)
+
+// ParseOption customizes ParseDesiredStateFiles.
+type ParseOption func(*parseOptions)
+
+type parseOptions struct {
+	environment map[string]string
+	stackName   string
+}
+
+// WithEnvironment enables strict variable interpolation using env. Every
+// ${VAR} reference without a default must resolve, otherwise parsing fails
+// with an *InterpolationError. Without this option variables are not
+// resolved from anywhere and expand to empty strings.
+func WithEnvironment(env map[string]string) ParseOption {
+	return func(o *parseOptions) {
+		if env == nil {
+			env = map[string]string{}
+		}
+		o.environment = env
+	}
+}
+
+// InterpolationError reports a variable that is referenced by a compose file
+// but missing from the interpolation environment.
+type InterpolationError struct {
+	File     string
+	Service  string
+	Field    string
+	Variable string
+}
+
+func (e *InterpolationError) Error() string {
+	if e.Service != "" {
+		return fmt.Sprintf("%s: service %q field %q: variable %q is not set", e.File, e.Service, e.Field, e.Variable)
+	}
+	return fmt.Sprintf("%s: field %q: variable %q is not set", e.File, e.Field, e.Variable)
+}
+
+// ParseEnvFile parses a .env file into an interpolation environment.
+// Variables in values are expanded from earlier entries only, never from the
+// process environment.
+func ParseEnvFile(body []byte) (map[string]string, error) {
+	env, err := dotenv.ParseWithLookup(bytes.NewReader(body), func(string) (string, bool) {
+		return "", false
+	})
+	if err != nil {
+		return nil, fmt.Errorf("parse env file: %w", err)
+	}
+	return env, nil
+}
+
+// EnvFingerprint folds an env file into a compose fingerprint so edits to
+// either trigger a re-parse.
+func EnvFingerprint(composeFingerprint string, env []byte) string {
+	combined := sha256.New()
+	combined.Write([]byte(composeFingerprint))
+	combined.Write([]byte{'\n'})
+	combined.Write(env)
+	return hex.EncodeToString(combined.Sum(nil))
+}
+
+// checkInterpolation walks a compose document and reports the first variable
+// reference that cannot be resolved from env. compose-go only reports the
+// document path in its error text, so the walk is done here to name the
+// service and field precisely.
+func checkInterpolation(file File, env map[string]string) error {
+	var doc map[string]any
+	if err := yaml.Unmarshal(file.Body, &doc); err != nil {
+		// Leave syntax errors to the loader, which reports them consistently.
+		return nil
+	}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+	return walkInterpolation(doc, nil, func(path []string, value string) error {
+		missing := ""
+		_, err := template.SubstituteWithOptions(value, lookup,
+			template.WithoutLogging,
+			template.WithReplacementFunction(func(substring string, mapping template.Mapping, cfg *template.Config) (string, error) {
+				replacement, applied, err := template.DefaultReplacementAppliedFunc(substring, mapping, cfg)
+				if err == nil && !applied && missing == "" {
+					missing = strings.Trim(substring, "${}")
+				}
+				return replacement, err
+			}),
+		)
+		if err != nil {
+			return fmt.Errorf("%s: %s: %w", file.Name, strings.Join(path, "."), err)
+		}
+		if missing == "" {
+			return nil
+		}
+		ierr := &InterpolationError{File: file.Name, Field: strings.Join(path, "."), Variable: missing}
+		if len(path) > 2 && path[0] == "services" {
+			ierr.Service = path[1]
+			ierr.Field = strings.Join(path[2:], ".")
+		}
+		return ierr
+	})
+}
+
+// walkInterpolation visits every string scalar in deterministic order.
+func walkInterpolation(node any, path []string, visit func([]string, string) error) error {
+	switch value := node.(type) {
+	case string:
+		return visit(path, value)
+	case map[string]any:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := walkInterpolation(value[key], append(path[:len(path):len(path)], key), visit); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for i, elem := range value {
+			if err := walkInterpolation(elem, append(path[:len(path):len(path)], strconv.Itoa(i)), visit); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package compose
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseDesiredStateFiles_Interpolation(t *testing.T) {
+	body := []byte(`
+services:
+  web:
+    image: registry.example.com/web:${TAG}
+    deploy:
+      replicas: ${REPLICAS:-2}
+  worker:
+    image: busybox:${WORKER_TAG:-latest}
+    command: ["sh", "-c", "echo $$HOME"]
+`)
+
+	env, err := ParseEnvFile([]byte("# release\nTAG=1.4.2\nREPLICAS=3\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	state, err := ParseDesiredStateFiles(context.Background(), []File{{Name: "compose.yml", Body: body}}, WithEnvironment(env))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := state.Services["web"].Image; got != "registry.example.com/web:1.4.2" {
+		t.Fatalf("unexpected web image: %q", got)
+	}
+	if got := state.Services["web"].Replicas; got != 3 {
+		t.Fatalf("unexpected web replicas: %d", got)
+	}
+	if got := state.Services["worker"].Image; got != "busybox:latest" {
+		t.Fatalf("expected default to apply, got %q", got)
+	}
+
+	_, err = ParseDesiredStateFiles(context.Background(), []File{{Name: "compose.yml", Body: body}}, WithEnvironment(map[string]string{}))
+	var ierr *InterpolationError
+	if !errors.As(err, &ierr) {
+		t.Fatalf("expected InterpolationError, got %v", err)
+	}
+	if ierr.Service != "web" || ierr.Field != "image" || ierr.Variable != "TAG" {
+		t.Fatalf("unexpected interpolation error: %+v", ierr)
+	}
+	if !strings.Contains(err.Error(), `service "web" field "image"`) {
+		t.Fatalf("expected service and field in message, got %q", err.Error())
+	}
+}
+
+func TestParseDesiredState_NoInterpolationByDefault(t *testing.T) {
+	state, err := ParseDesiredState(context.Background(), []byte("services:\n  web:\n    image: nginx:${TAG:-1.27}\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := state.Services["web"].Image; got != "nginx:1.27" {
+		t.Fatalf("unexpected image: %q", got)
+	}
+}
+
+func TestParseEnvFile_Invalid(t *testing.T) {
+	if _, err := ParseEnvFile([]byte("TAG='unterminated\n")); err == nil {
+		t.Fatal("expected error for malformed env file")
+	}
+}
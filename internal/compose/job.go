@@ -0,0 +1,53 @@
+package compose
+
+import (
+	"fmt"
+	"math"
+)
+
+// resolveJob returns the max_concurrent and total_completions of a
+// replicated job. `docker stack deploy` sets both to the replica count; jobs
+// created with other values, e.g. `docker service create --max-concurrent`,
+// can declare them in the service's x-swarm-sentinel extension:
+//
+//	x-swarm-sentinel:
+//	  max_concurrent: 2
+//	  total_completions: 10
+func resolveJob(mode string, replicas int, settings map[string]any) (int, int, error) {
+	if mode != replicatedJobMode {
+		return 0, 0, nil
+	}
+	maxConcurrent, err := jobSetting(settings, "max_concurrent", replicas)
+	if err != nil {
+		return 0, 0, err
+	}
+	totalCompletions, err := jobSetting(settings, "total_completions", replicas)
+	if err != nil {
+		return 0, 0, err
+	}
+	return maxConcurrent, totalCompletions, nil
+}
+
+func jobSetting(settings map[string]any, key string, fallback int) (int, error) {
+	raw, ok := settings[key]
+	if !ok {
+		return fallback, nil
+	}
+	var value float64
+	switch v := raw.(type) {
+	case int:
+		value = float64(v)
+	case int64:
+		value = float64(v)
+	case uint64:
+		value = float64(v)
+	case float64:
+		value = v
+	default:
+		return 0, fmt.Errorf("%s must be a number", key)
+	}
+	if value < 1 || value != math.Trunc(value) || value > math.MaxInt32 {
+		return 0, fmt.Errorf("%s must be a positive whole number", key)
+	}
+	return int(value), nil
+}
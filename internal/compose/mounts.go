@@ -0,0 +1,59 @@
+package compose
+
+import (
+	"sort"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// Mount is a bind, volume or tmpfs mount of a service; mounts are keyed by
+// Target. Source is the host path of a bind mount (relative paths are kept as
+// written, since the deploy directory is unknown) or the Swarm name of a
+// named volume, and is empty for anonymous volumes and tmpfs mounts. Driver
+// and DriverOptions are set for named volumes the stack defines with a driver.
+type Mount struct {
+	Type          string
+	Source        string
+	Target        string
+	ReadOnly      bool
+	Driver        string
+	DriverOptions map[string]string
+}
+
+// resolveMounts converts a service's volumes the way `docker stack deploy`
+// does. Like networks, mounts are only resolved for a known stack since named
+// volumes are prefixed with the stack name.
+func resolveMounts(project *types.Project, service types.ServiceConfig, stack string) []Mount {
+	if stack == "" {
+		return nil
+	}
+
+	mounts := make([]Mount, 0, len(service.Volumes))
+	for _, volume := range service.Volumes {
+		mount := Mount{
+			Type:     volume.Type,
+			Source:   volume.Source,
+			Target:   volume.Target,
+			ReadOnly: volume.ReadOnly,
+		}
+		switch volume.Type {
+		case types.VolumeTypeTmpfs:
+			mount.Source = ""
+		case types.VolumeTypeVolume:
+			config, ok := project.Volumes[volume.Source]
+			if volume.Source == "" || !ok {
+				break
+			}
+			mount.Source = scopedName(project, volume.Source, config.Name, bool(config.External), stack)
+			if !config.External && (config.Driver != "" || len(config.DriverOpts) > 0) {
+				mount.Driver = config.Driver
+				mount.DriverOptions = copyLabels(types.Labels(config.DriverOpts))
+			}
+		}
+		mounts = append(mounts, mount)
+	}
+	sort.Slice(mounts, func(i, j int) bool {
+		return mounts[i].Target < mounts[j].Target
+	})
+	return mounts
+}
@@ -0,0 +1,72 @@
+package compose
+
+import (
+	"sort"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// NetworkAttachment is a network a service joins, with the aliases it is
+// reachable under. Aliases are sorted and include the service name, which
+// `docker stack deploy` always adds.
+type NetworkAttachment struct {
+	Name    string
+	Aliases []string
+}
+
+// WithStackName resolves network and volume names the way `docker stack
+// deploy` does for stack: networks and volumes declared in the compose file
+// are prefixed with the stack name unless they are external or set an
+// explicit name. Without it network attachments and mounts are not resolved.
+func WithStackName(stack string) ParseOption {
+	return func(o *parseOptions) {
+		o.stackName = stack
+	}
+}
+
+func resolveNetworks(project *types.Project, service types.ServiceConfig, serviceName, stack string) []NetworkAttachment {
+	if stack == "" {
+		return nil
+	}
+
+	attachments := make([]NetworkAttachment, 0, len(service.Networks))
+	for key, config := range service.Networks {
+		aliases := []string{serviceName}
+		if config != nil {
+			aliases = append(aliases, config.Aliases...)
+		}
+		attachments = append(attachments, NetworkAttachment{
+			Name:    resolveNetworkName(project, key, stack),
+			Aliases: normalizeNames(aliases),
+		})
+	}
+	sort.Slice(attachments, func(i, j int) bool {
+		return attachments[i].Name < attachments[j].Name
+	})
+	return attachments
+}
+
+// resolveNetworkName returns the Swarm name of a compose network.
+func resolveNetworkName(project *types.Project, key, stack string) string {
+	network, ok := project.Networks[key]
+	if !ok {
+		return stack + "_" + key
+	}
+	return scopedName(project, key, network.Name, bool(network.External), stack)
+}
+
+// scopedName returns the Swarm name of a top-level network or volume. The
+// loader names every resource <project>_<key> unless it sets a name, so that
+// default is replaced by the stack prefix as `docker stack deploy` does.
+func scopedName(project *types.Project, key, name string, external bool, stack string) string {
+	if external {
+		if name != "" {
+			return name
+		}
+		return key
+	}
+	if name != "" && name != project.Name+"_"+key {
+		return name
+	}
+	return stack + "_" + key
+}
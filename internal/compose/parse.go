@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strconv"
+	"time"
 
 	"github.com/compose-spec/compose-go/v2/loader"
 	"github.com/compose-spec/compose-go/v2/types"
@@ -13,20 +15,29 @@ import (
 const (
 	defaultDeployMode   = "replicated"
 	globalDeployMode    = "global"
+	replicatedJobMode   = "replicated-job"
+	globalJobMode       = "global-job"
 	defaultServiceScale = 1
+	defaultEndpointMode = "vip"
+	defaultPortProtocol = "tcp"
+	defaultPortMode     = "ingress"
 )
 
 // DesiredState represents the normalized desired state from a compose file.
+// Fingerprint is the semantic fingerprint of the loaded project: it ignores
+// formatting, comments and key order, so it only changes when the compose
+// content itself does.
 type DesiredState struct {
-	Services map[string]DesiredService
+	Services    map[string]DesiredService
+	Fingerprint string
 }
 
 // DesiredService captures the fields we track for a service.
 //
 // Global Mode Comparison Strategy:
-// For global mode services, Replicas is set to 0 at parse time because the actual
-// replica count depends on the number of nodes in the cluster, which is only known
-// at runtime. When comparing desired vs actual state:
+// For global and global-job services, Replicas is set to 0 at parse time because the
+// actual replica count depends on the number of nodes in the cluster, which is only
+// known at runtime. When comparing desired vs actual state:
 //   - For replicated mode: compare DesiredService.Replicas with ActualService.DesiredReplicas
 //   - For global mode: skip replica count comparison (Replicas=0 indicates global)
 //     and rely on ActualService.DesiredReplicas from Swarm's ServiceStatus.DesiredTasks
@@ -35,46 +46,163 @@ type DesiredState struct {
 // comparison logic.
 type DesiredService struct {
 	Image    string   // Expected image reference (may include digest in actual state)
-	Mode     string   // "replicated" or "global"
-	Replicas int      // Desired replica count; 0 for global mode (see above)
+	Mode     string   // "replicated", "global", "replicated-job" or "global-job"
+	Replicas int      // Desired replica count; 0 for global modes (see above)
 	Configs  []string // Sorted list of config names attached to the service
 	Secrets  []string // Sorted list of secret names attached to the service
+	// MaxConcurrent and TotalCompletions are set for replicated jobs only.
+	MaxConcurrent    int
+	TotalCompletions int
+	// Ports lists published ports sorted by target port and protocol.
+	Ports []PortMapping
+	// EndpointMode is "vip" or "dnsrr".
+	EndpointMode string
+	// Environment maps variable names to HashEnvValue digests; values are
+	// never kept in plain text.
+	Environment map[string]string
+	// IgnoreEnv lists variables excluded from drift detection, from the
+	// service's x-swarm-sentinel.ignore_env. Entries ending in "*" are prefixes.
+	IgnoreEnv []string
+	// Labels holds deploy.labels, which become service labels in Swarm.
+	Labels map[string]string
+	// ContainerLabels holds the service's labels, which Swarm applies to
+	// its containers.
+	ContainerLabels map[string]string
+	// Resources holds deploy.resources.
+	Resources Resources
+	// Placement holds deploy.placement.
+	Placement Placement
+	// Networks lists the networks the service joins, sorted by name. It is
+	// only resolved when parsing with WithStackName, since network names
+	// depend on the stack.
+	Networks []NetworkAttachment
+	// UpdateConfig, RollbackConfig and RestartPolicy hold the deploy
+	// policies with Swarm's defaults filled in.
+	UpdateConfig   UpdatePolicy
+	RollbackConfig UpdatePolicy
+	RestartPolicy  RestartPolicy
+	// Healthcheck is nil when the service does not declare one.
+	Healthcheck *Healthcheck
+	// Mounts lists the service's volumes sorted by target. Like Networks it
+	// is only resolved when parsing with WithStackName.
+	Mounts []Mount
+	// Command and Entrypoint are nil when the image's are used.
+	// StopGracePeriod is 0 when unset.
+	Command         []string
+	Entrypoint      []string
+	User            string
+	WorkingDir      string
+	Hostname        string
+	StopSignal      string
+	StopGracePeriod time.Duration
+}
+
+// Placement holds a service's scheduling rules. Constraints are sorted;
+// Preferences are spread descriptors in priority order. MaxReplicasPerNode is
+// 0 when unlimited.
+type Placement struct {
+	Constraints        []string
+	Preferences        []string
+	MaxReplicasPerNode uint64
+}
+
+// Resources holds a service's resource limits and reservations in the units
+// Swarm stores them in.
+type Resources struct {
+	Limits       ResourceSpec
+	Reservations ResourceSpec
+}
+
+// ResourceSpec is one side of a service's resources. Zero values are unset.
+// Generic maps discrete generic resource kinds to their counts. As in Swarm,
+// Pids is only set for limits and Generic only for reservations.
+type ResourceSpec struct {
+	NanoCPUs    int64
+	MemoryBytes int64
+	Pids        int64
+	Generic     map[string]int64
+}
+
+// PortMapping is a port published by a service. Published is 0 when Swarm
+// assigns the published port; Mode is "ingress" or "host".
+type PortMapping struct {
+	Target    uint32
+	Published uint32
+	Protocol  string
+	Mode      string
+}
+
+// File is a single compose document. Name is used in parse errors.
+type File struct {
+	Name string
+	Body []byte
 }
 
 // ParseDesiredState parses compose content into a normalized desired state model.
 func ParseDesiredState(ctx context.Context, body []byte) (DesiredState, error) {
-	if len(body) == 0 {
-		return DesiredState{}, errors.New("compose body is empty")
+	return ParseDesiredStateFiles(ctx, []File{{Name: "compose.yml", Body: body}})
+}
+
+// ParseDesiredStateFiles merges compose files in order, as
+// `docker stack deploy -c base.yml -c override.yml` does, and parses the
+// result into a normalized desired state model. Invalid content is reported
+// as a *ParseError.
+func ParseDesiredStateFiles(ctx context.Context, files []File, opts ...ParseOption) (DesiredState, error) {
+	if len(files) == 0 {
+		return DesiredState{}, errors.New("no compose files")
+	}
+	options := parseOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	configFiles := make([]types.ConfigFile, 0, len(files))
+	for _, file := range files {
+		if len(file.Body) == 0 {
+			return DesiredState{}, &ParseError{File: file.Name, Message: "compose body is empty"}
+		}
+		if options.environment != nil {
+			if err := checkInterpolation(file, options.environment); err != nil {
+				return DesiredState{}, newParseError(files, err)
+			}
+		}
+		configFiles = append(configFiles, types.ConfigFile{
+			Filename: file.Name,
+			Content:  file.Body,
+		})
 	}
 
 	details := types.ConfigDetails{
-		WorkingDir: ".",
-		ConfigFiles: []types.ConfigFile{
-			{
-				Filename: "compose.yml",
-				Content:  body,
-			},
-		},
+		WorkingDir:  ".",
+		ConfigFiles: configFiles,
 		Environment: types.Mapping{},
 	}
+	for key, value := range options.environment {
+		details.Environment[key] = value
+	}
 
 	project, err := loader.LoadWithContext(ctx, details, func(opts *loader.Options) {
 		opts.SetProjectName("swarm-sentinel", false)
 	})
 	if err != nil {
-		return DesiredState{}, fmt.Errorf("load compose: %w", err)
+		return DesiredState{}, newParseError(files, err)
 	}
 	if len(project.Services) == 0 {
-		return DesiredState{}, errors.New("compose has no services")
+		return DesiredState{}, &ParseError{Message: "compose has no services"}
+	}
+
+	fingerprint, err := semanticFingerprint(project)
+	if err != nil {
+		return DesiredState{}, err
 	}
 
 	state := DesiredState{
-		Services: make(map[string]DesiredService, len(project.Services)),
+		Services:    make(map[string]DesiredService, len(project.Services)),
+		Fingerprint: fingerprint,
 	}
 
 	for name, service := range project.Services {
 		if service.Image == "" {
-			return DesiredState{}, fmt.Errorf("service %q missing image", name)
+			return DesiredState{}, serviceParseError(files, name, "image", errors.New("missing image"))
 		}
 
 		mode := defaultDeployMode
@@ -82,8 +210,14 @@ func ParseDesiredState(ctx context.Context, body []byte) (DesiredState, error) {
 			mode = service.Deploy.Mode
 		}
 
+		switch mode {
+		case defaultDeployMode, globalDeployMode, replicatedJobMode, globalJobMode:
+		default:
+			return DesiredState{}, serviceParseError(files, name, "deploy.mode", fmt.Errorf("unsupported deploy mode %q", mode))
+		}
+
 		replicas := defaultServiceScale
-		if mode == globalDeployMode {
+		if mode == globalDeployMode || mode == globalJobMode {
 			// Global mode replicas are set to 0 at parse time because the actual
 			// count depends on the number of nodes in the cluster, which is only
 			// known at runtime. The actual state uses ServiceStatus.DesiredTasks.
@@ -96,20 +230,72 @@ func ParseDesiredState(ctx context.Context, body []byte) (DesiredState, error) {
 
 		configs, err := resolveConfigNames(service.Configs, project.Configs)
 		if err != nil {
-			return DesiredState{}, fmt.Errorf("service %q configs: %w", name, err)
+			return DesiredState{}, serviceParseError(files, name, "configs", err)
 		}
 
 		secrets, err := resolveSecretNames(service.Secrets, project.Secrets)
 		if err != nil {
-			return DesiredState{}, fmt.Errorf("service %q secrets: %w", name, err)
+			return DesiredState{}, serviceParseError(files, name, "secrets", err)
+		}
+
+		ports, err := resolvePorts(service.Ports)
+		if err != nil {
+			return DesiredState{}, serviceParseError(files, name, "ports", err)
+		}
+
+		ignoreEnv, err := resolveIgnoreEnv(service.Extensions)
+		if err != nil {
+			return DesiredState{}, serviceParseError(files, name, sentinelExtension, err)
+		}
+
+		settings, err := sentinelSettings(service.Extensions)
+		if err != nil {
+			return DesiredState{}, serviceParseError(files, name, sentinelExtension, err)
+		}
+		maxConcurrent, totalCompletions, err := resolveJob(mode, replicas, settings)
+		if err != nil {
+			return DesiredState{}, serviceParseError(files, name, sentinelExtension, err)
+		}
+
+		healthcheck, err := resolveHealthcheck(service.HealthCheck)
+		if err != nil {
+			return DesiredState{}, serviceParseError(files, name, "healthcheck", err)
+		}
+
+		endpointMode := defaultEndpointMode
+		if service.Deploy != nil && service.Deploy.EndpointMode != "" {
+			endpointMode = service.Deploy.EndpointMode
 		}
 
 		state.Services[name] = DesiredService{
-			Image:    service.Image,
-			Mode:     mode,
-			Replicas: replicas,
-			Configs:  configs,
-			Secrets:  secrets,
+			Image:            service.Image,
+			Mode:             mode,
+			Replicas:         replicas,
+			MaxConcurrent:    maxConcurrent,
+			TotalCompletions: totalCompletions,
+			Configs:          configs,
+			Secrets:          secrets,
+			Ports:            ports,
+			EndpointMode:     endpointMode,
+			Environment:      resolveEnvironment(service.Environment),
+			IgnoreEnv:        ignoreEnv,
+			Labels:           copyLabels(deployLabels(service.Deploy)),
+			ContainerLabels:  copyLabels(service.Labels),
+			Resources:        resolveResources(service.Deploy),
+			Placement:        resolvePlacement(service.Deploy),
+			Networks:         resolveNetworks(project, service, name, options.stackName),
+			UpdateConfig:     resolveUpdatePolicy(updateConfig(service.Deploy)),
+			RollbackConfig:   resolveUpdatePolicy(rollbackConfig(service.Deploy)),
+			RestartPolicy:    resolveRestartPolicy(service),
+			Healthcheck:      healthcheck,
+			Mounts:           resolveMounts(project, service, options.stackName),
+			Command:          copyArgs(service.Command),
+			Entrypoint:       copyArgs(service.Entrypoint),
+			User:             service.User,
+			WorkingDir:       service.WorkingDir,
+			Hostname:         service.Hostname,
+			StopSignal:       service.StopSignal,
+			StopGracePeriod:  durationValue(service.StopGracePeriod),
 		}
 	}
 
@@ -166,6 +352,148 @@ func resolveSecretNames(refs []types.ServiceSecretConfig, secrets types.Secrets)
 	return normalizeNames(names), nil
 }
 
+// resolveResources converts deploy.resources the way `docker stack deploy`
+// does: CPUs become nano CPUs and only discrete generic resources are kept.
+func resolveResources(deploy *types.DeployConfig) Resources {
+	var resources Resources
+	if deploy == nil {
+		return resources
+	}
+	// Only the fields Swarm stores are read, mirroring what
+	// `docker stack deploy` sends: pids is a limit and generic resources
+	// are a reservation.
+	if limits := deploy.Resources.Limits; limits != nil {
+		resources.Limits = ResourceSpec{
+			NanoCPUs:    nanoCPUs(limits.NanoCPUs),
+			MemoryBytes: int64(limits.MemoryBytes),
+			Pids:        limits.Pids,
+		}
+	}
+	if reservations := deploy.Resources.Reservations; reservations != nil {
+		resources.Reservations = ResourceSpec{
+			NanoCPUs:    nanoCPUs(reservations.NanoCPUs),
+			MemoryBytes: int64(reservations.MemoryBytes),
+		}
+		for _, generic := range reservations.GenericResources {
+			if generic.DiscreteResourceSpec == nil {
+				continue
+			}
+			if resources.Reservations.Generic == nil {
+				resources.Reservations.Generic = map[string]int64{}
+			}
+			resources.Reservations.Generic[generic.DiscreteResourceSpec.Kind] = generic.DiscreteResourceSpec.Value
+		}
+	}
+	return resources
+}
+
+func nanoCPUs(cpus types.NanoCPUs) int64 {
+	return int64(float64(cpus) * 1e9)
+}
+
+func resolvePlacement(deploy *types.DeployConfig) Placement {
+	if deploy == nil {
+		return Placement{}
+	}
+	placement := Placement{MaxReplicasPerNode: deploy.Placement.MaxReplicas}
+	if len(deploy.Placement.Constraints) > 0 {
+		placement.Constraints = append([]string(nil), deploy.Placement.Constraints...)
+		sort.Strings(placement.Constraints)
+	}
+	for _, preference := range deploy.Placement.Preferences {
+		placement.Preferences = append(placement.Preferences, preference.Spread)
+	}
+	return placement
+}
+
+func updateConfig(deploy *types.DeployConfig) *types.UpdateConfig {
+	if deploy == nil {
+		return nil
+	}
+	return deploy.UpdateConfig
+}
+
+func rollbackConfig(deploy *types.DeployConfig) *types.UpdateConfig {
+	if deploy == nil {
+		return nil
+	}
+	return deploy.RollbackConfig
+}
+
+func copyArgs(args types.ShellCommand) []string {
+	if len(args) == 0 {
+		return nil
+	}
+	return append([]string(nil), args...)
+}
+
+func durationValue(d *types.Duration) time.Duration {
+	if d == nil {
+		return 0
+	}
+	return time.Duration(*d)
+}
+
+func deployLabels(deploy *types.DeployConfig) types.Labels {
+	if deploy == nil {
+		return nil
+	}
+	return deploy.Labels
+}
+
+func copyLabels(labels types.Labels) map[string]string {
+	if len(labels) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(labels))
+	for key, value := range labels {
+		result[key] = value
+	}
+	return result
+}
+
+// resolvePorts converts compose ports into the form Swarm stores them in,
+// applying the same protocol and publish mode defaults.
+func resolvePorts(ports []types.ServicePortConfig) ([]PortMapping, error) {
+	if len(ports) == 0 {
+		return nil, nil
+	}
+
+	result := make([]PortMapping, 0, len(ports))
+	for _, port := range ports {
+		mapping := PortMapping{
+			Target:   port.Target,
+			Protocol: port.Protocol,
+			Mode:     port.Mode,
+		}
+		if mapping.Protocol == "" {
+			mapping.Protocol = defaultPortProtocol
+		}
+		if mapping.Mode == "" {
+			mapping.Mode = defaultPortMode
+		}
+		if port.Published != "" {
+			published, err := strconv.ParseUint(port.Published, 10, 16)
+			if err != nil {
+				return nil, fmt.Errorf("invalid published port %q", port.Published)
+			}
+			mapping.Published = uint32(published)
+		}
+		result = append(result, mapping)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Target != result[j].Target {
+			return result[i].Target < result[j].Target
+		}
+		if result[i].Protocol != result[j].Protocol {
+			return result[i].Protocol < result[j].Protocol
+		}
+		return result[i].Published < result[j].Published
+	})
+	return result, nil
+}
+
 func normalizeNames(values []string) []string {
 	if len(values) == 0 {
 		return nil
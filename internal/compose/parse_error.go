@@ -0,0 +1,201 @@
+package compose
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	yaml4 "go.yaml.in/yaml/v4"
+	"gopkg.in/yaml.v3"
+)
+
+// ParseError locates a compose parse failure as precisely as possible. Line
+// and Column are 1-based and zero when unknown; Service and Field are empty
+// when the failure is not tied to a service.
+type ParseError struct {
+	File    string
+	Line    int
+	Column  int
+	Service string
+	Field   string
+	Message string
+
+	err error
+}
+
+func (e *ParseError) Error() string {
+	var b strings.Builder
+	if e.File != "" {
+		b.WriteString(e.File)
+		if e.Line > 0 {
+			fmt.Fprintf(&b, ":%d", e.Line)
+			if e.Column > 0 {
+				fmt.Fprintf(&b, ":%d", e.Column)
+			}
+		}
+		b.WriteString(": ")
+	}
+	if e.Service != "" {
+		fmt.Fprintf(&b, "service %q ", e.Service)
+	}
+	if e.Field != "" {
+		fmt.Fprintf(&b, "field %q", e.Field)
+	}
+	if e.Service != "" || e.Field != "" {
+		b.WriteString(": ")
+	}
+	b.WriteString(e.Message)
+	return b.String()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.err
+}
+
+var (
+	// compose-go reports the failing document path in its error text, e.g.
+	// "validating compose.yml: services.web.ports must be a array" or
+	// "error while interpolating services.web.deploy.replicas: ...".
+	servicePathPattern    = regexp.MustCompile(`services\.([^.\s:]+)(?:\.([^\s:]+))?`)
+	additionalPropPattern = regexp.MustCompile(`additional propert(?:y|ies) '([^']+)'`)
+)
+
+// newParseError turns a loader failure into a ParseError. Syntax errors carry
+// their position from the YAML parser; schema and interpolation errors only
+// name a document path, which is resolved to a position by walking files.
+func newParseError(files []File, err error) *ParseError {
+	var perr *ParseError
+	if errors.As(err, &perr) {
+		return perr
+	}
+	var ierr *InterpolationError
+	if errors.As(err, &ierr) {
+		perr = &ParseError{
+			File:    ierr.File,
+			Service: ierr.Service,
+			Field:   ierr.Field,
+			Message: fmt.Sprintf("variable %q is not set", ierr.Variable),
+			err:     err,
+		}
+		locateParseError(files, perr)
+		return perr
+	}
+
+	message := err.Error()
+	perr = &ParseError{Message: message, err: err}
+	for _, file := range files {
+		for _, prefix := range []string{"failed to parse " + file.Name + ": ", "validating " + file.Name + ": "} {
+			if idx := strings.Index(message, prefix); idx >= 0 {
+				perr.File = file.Name
+				perr.Message = message[idx+len(prefix):]
+			}
+		}
+	}
+
+	var syntax *yaml4.ParserError
+	if errors.As(err, &syntax) {
+		perr.Line = syntax.Line
+		perr.Column = syntax.Column
+		perr.Message = syntax.Message
+		return perr
+	}
+
+	if match := servicePathPattern.FindStringSubmatch(perr.Message); match != nil {
+		perr.Service = match[1]
+		perr.Field = match[2]
+		if perr.Field == "" {
+			if prop := additionalPropPattern.FindStringSubmatch(perr.Message); prop != nil {
+				perr.Field = prop[1]
+			}
+		}
+	}
+	locateParseError(files, perr)
+	return perr
+}
+
+// serviceParseError reports a problem found after loading, such as a service
+// without an image.
+func serviceParseError(files []File, service, field string, err error) *ParseError {
+	perr := &ParseError{
+		Service: service,
+		Field:   field,
+		Message: err.Error(),
+		err:     err,
+	}
+	locateParseError(files, perr)
+	return perr
+}
+
+// locateParseError fills File, Line and Column from the service and field the
+// error names. Overlays are searched last to first, since the last file to
+// set a key is the one whose value was loaded.
+func locateParseError(files []File, perr *ParseError) {
+	if perr.Service == "" || perr.Line > 0 {
+		return
+	}
+	path := []string{"services", perr.Service}
+	if perr.Field != "" {
+		path = append(path, strings.Split(perr.Field, ".")...)
+	}
+	for i := len(files) - 1; i >= 0; i-- {
+		file := files[i]
+		if perr.File != "" && perr.File != file.Name {
+			continue
+		}
+		var doc yaml.Node
+		if err := yaml.Unmarshal(file.Body, &doc); err != nil {
+			continue
+		}
+		node, depth := lookupYAMLPath(&doc, path)
+		if depth < 2 {
+			continue
+		}
+		perr.File = file.Name
+		perr.Line = node.Line
+		perr.Column = node.Column
+		return
+	}
+}
+
+// lookupYAMLPath descends path as far as it exists and returns the deepest
+// node found with the number of segments matched. Mapping entries resolve to
+// their key so positions point at the offending field name.
+func lookupYAMLPath(doc *yaml.Node, path []string) (*yaml.Node, int) {
+	node := doc
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	found, depth := node, 0
+	for _, segment := range path {
+		if segment == "[]" {
+			continue
+		}
+		switch node.Kind {
+		case yaml.MappingNode:
+			var next *yaml.Node
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == segment {
+					found, next = node.Content[i], node.Content[i+1]
+					break
+				}
+			}
+			if next == nil {
+				return found, depth
+			}
+			node = next
+		case yaml.SequenceNode:
+			idx, err := strconv.Atoi(segment)
+			if err != nil || idx < 0 || idx >= len(node.Content) {
+				return found, depth
+			}
+			node = node.Content[idx]
+			found = node
+		default:
+			return found, depth
+		}
+		depth++
+	}
+	return found, depth
+}
@@ -2,8 +2,11 @@ package compose
 
 import (
 	"context"
+	"errors"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestParseDesiredState_Basic(t *testing.T) {
@@ -68,6 +71,58 @@ services:
 	}
 }
 
+func TestParseDesiredState_JobModes(t *testing.T) {
+	composeYAML := `
+services:
+  migrate:
+    image: busybox:latest
+    deploy:
+      mode: replicated-job
+      replicas: 2
+  backfill:
+    image: busybox:latest
+    deploy:
+      mode: replicated-job
+    x-swarm-sentinel:
+      max_concurrent: 2
+      total_completions: 10
+  prune:
+    image: busybox:latest
+    deploy:
+      mode: global-job
+`
+
+	state, err := ParseDesiredState(context.Background(), []byte(composeYAML))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := state.Services["migrate"]; got.Mode != replicatedJobMode || got.Replicas != 2 {
+		t.Fatalf("unexpected replicated job: %s %d", got.Mode, got.Replicas)
+	}
+	if got := state.Services["migrate"]; got.MaxConcurrent != 2 || got.TotalCompletions != 2 {
+		t.Fatalf("expected job settings to default to replicas, got %d %d", got.MaxConcurrent, got.TotalCompletions)
+	}
+	if got := state.Services["backfill"]; got.MaxConcurrent != 2 || got.TotalCompletions != 10 {
+		t.Fatalf("unexpected job overrides: %d %d", got.MaxConcurrent, got.TotalCompletions)
+	}
+	if got := state.Services["prune"]; got.Mode != globalJobMode || got.Replicas != 0 || got.TotalCompletions != 0 {
+		t.Fatalf("unexpected global job: %s %d %d", got.Mode, got.Replicas, got.TotalCompletions)
+	}
+
+	_, err = ParseDesiredState(context.Background(), []byte("services:\n  migrate:\n    image: busybox\n    deploy:\n      mode: replicated-job\n    x-swarm-sentinel:\n      total_completions: 0\n"))
+	var jobErr *ParseError
+	if !errors.As(err, &jobErr) || jobErr.Service != "migrate" || !strings.Contains(jobErr.Message, "total_completions") {
+		t.Fatalf("expected total_completions error on migrate, got %v", err)
+	}
+
+	_, err = ParseDesiredState(context.Background(), []byte("services:\n  web:\n    image: nginx\n    deploy:\n      mode: daemonset\n"))
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Field != "deploy.mode" || perr.Line != 5 {
+		t.Fatalf("expected located deploy mode error, got %v", err)
+	}
+}
+
 func TestParseDesiredState_ConfigSecretNormalization(t *testing.T) {
 	composeYAML := `
 name: prod
@@ -110,6 +165,394 @@ secrets:
 	}
 }
 
+func TestParseDesiredState_PortsAndEndpointMode(t *testing.T) {
+	composeYAML := `
+services:
+  web:
+    image: nginx:1.27
+    ports:
+      - "8443:443"
+      - 80
+      - target: 22
+        published: "2222"
+        protocol: tcp
+        mode: host
+    deploy:
+      endpoint_mode: dnsrr
+  api:
+    image: example/api:1
+`
+
+	state, err := ParseDesiredState(context.Background(), []byte(composeYAML))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	web := state.Services["web"]
+	want := []PortMapping{
+		{Target: 22, Published: 2222, Protocol: "tcp", Mode: "host"},
+		{Target: 80, Protocol: "tcp", Mode: "ingress"},
+		{Target: 443, Published: 8443, Protocol: "tcp", Mode: "ingress"},
+	}
+	if !reflect.DeepEqual(web.Ports, want) {
+		t.Fatalf("unexpected ports: %+v", web.Ports)
+	}
+	if web.EndpointMode != "dnsrr" {
+		t.Fatalf("unexpected endpoint mode: %q", web.EndpointMode)
+	}
+	if api := state.Services["api"]; api.Ports != nil || api.EndpointMode != "vip" {
+		t.Fatalf("expected no ports and vip default, got %+v", api)
+	}
+}
+
+func TestParseDesiredState_Environment(t *testing.T) {
+	composeYAML := `
+services:
+  web:
+    image: nginx:1.27
+    environment:
+      LOG_LEVEL: info
+      EMPTY: ""
+      FROM_SHELL:
+    x-swarm-sentinel:
+      ignore_env: [OTEL_*, BUILD_ID]
+`
+
+	state, err := ParseDesiredState(context.Background(), []byte(composeYAML))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	web := state.Services["web"]
+	want := map[string]string{
+		"LOG_LEVEL":  HashEnvValue("info"),
+		"EMPTY":      HashEnvValue(""),
+		"FROM_SHELL": "",
+	}
+	if !reflect.DeepEqual(web.Environment, want) {
+		t.Fatalf("unexpected environment: %+v", web.Environment)
+	}
+	if !reflect.DeepEqual(web.IgnoreEnv, []string{"BUILD_ID", "OTEL_*"}) {
+		t.Fatalf("unexpected ignore_env: %+v", web.IgnoreEnv)
+	}
+
+	_, err = ParseDesiredState(context.Background(), []byte("services:\n  web:\n    image: nginx\n    x-swarm-sentinel:\n      ignore_env: BUILD_ID\n"))
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Service != "web" || perr.Field != "x-swarm-sentinel" {
+		t.Fatalf("expected ignore_env error on web, got %v", err)
+	}
+}
+
+func TestParseDesiredState_Labels(t *testing.T) {
+	composeYAML := `
+services:
+  web:
+    image: nginx:1.27
+    labels:
+      traefik.docker.network: proxy
+    deploy:
+      labels:
+        traefik.enable: "true"
+`
+
+	state, err := ParseDesiredState(context.Background(), []byte(composeYAML))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	web := state.Services["web"]
+	if !reflect.DeepEqual(web.Labels, map[string]string{"traefik.enable": "true"}) {
+		t.Fatalf("unexpected service labels: %+v", web.Labels)
+	}
+	if !reflect.DeepEqual(web.ContainerLabels, map[string]string{"traefik.docker.network": "proxy"}) {
+		t.Fatalf("unexpected container labels: %+v", web.ContainerLabels)
+	}
+}
+
+func TestParseDesiredState_Resources(t *testing.T) {
+	composeYAML := `
+services:
+  web:
+    image: nginx:1.27
+    deploy:
+      resources:
+        limits:
+          cpus: "0.5"
+          memory: 512M
+          pids: 100
+        reservations:
+          cpus: "0.25"
+          memory: 128M
+          generic_resources:
+            - discrete_resource_spec:
+                kind: gpu
+                value: 2
+`
+
+	state, err := ParseDesiredState(context.Background(), []byte(composeYAML))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := Resources{
+		Limits:       ResourceSpec{NanoCPUs: 500000000, MemoryBytes: 512 * 1024 * 1024, Pids: 100},
+		Reservations: ResourceSpec{NanoCPUs: 250000000, MemoryBytes: 128 * 1024 * 1024, Generic: map[string]int64{"gpu": 2}},
+	}
+	if got := state.Services["web"].Resources; !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected resources: %+v", got)
+	}
+}
+
+func TestParseDesiredState_Placement(t *testing.T) {
+	composeYAML := `
+services:
+  web:
+    image: nginx:1.27
+    deploy:
+      placement:
+        constraints:
+          - node.role == worker
+          - node.labels.pool == web
+        preferences:
+          - spread: node.labels.zone
+          - spread: node.labels.rack
+        max_replicas_per_node: 2
+`
+
+	state, err := ParseDesiredState(context.Background(), []byte(composeYAML))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := Placement{
+		Constraints:        []string{"node.labels.pool == web", "node.role == worker"},
+		Preferences:        []string{"node.labels.zone", "node.labels.rack"},
+		MaxReplicasPerNode: 2,
+	}
+	if got := state.Services["web"].Placement; !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected placement: %+v", got)
+	}
+}
+
+func TestParseDesiredStateFiles_Networks(t *testing.T) {
+	body := []byte(`
+services:
+  web:
+    image: nginx:1.27
+    networks:
+      frontend:
+        aliases: [www]
+      shared: {}
+      named: {}
+  worker:
+    image: busybox:latest
+networks:
+  frontend: {}
+  shared:
+    external: true
+  named:
+    name: custom-net
+`)
+
+	unscoped, err := ParseDesiredState(context.Background(), body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := unscoped.Services["web"].Networks; got != nil {
+		t.Fatalf("expected networks to be unresolved without a stack, got %+v", got)
+	}
+
+	state, err := ParseDesiredStateFiles(context.Background(), []File{{Name: "compose.yml", Body: body}}, WithStackName("prod"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []NetworkAttachment{
+		{Name: "custom-net", Aliases: []string{"web"}},
+		{Name: "prod_frontend", Aliases: []string{"web", "www"}},
+		{Name: "shared", Aliases: []string{"web"}},
+	}
+	if got := state.Services["web"].Networks; !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected web networks: %+v", got)
+	}
+	wantDefault := []NetworkAttachment{{Name: "prod_default", Aliases: []string{"worker"}}}
+	if got := state.Services["worker"].Networks; !reflect.DeepEqual(got, wantDefault) {
+		t.Fatalf("unexpected worker networks: %+v", got)
+	}
+}
+
+func TestParseDesiredState_Policies(t *testing.T) {
+	composeYAML := `
+services:
+  web:
+    image: nginx:1.27
+    deploy:
+      update_config:
+        parallelism: 0
+        delay: 10s
+        failure_action: rollback
+        order: start-first
+      restart_policy:
+        condition: on-failure
+        max_attempts: 3
+  worker:
+    image: busybox:latest
+    restart: "on-failure:5"
+`
+
+	state, err := ParseDesiredState(context.Background(), []byte(composeYAML))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	web := state.Services["web"]
+	wantUpdate := UpdatePolicy{
+		Parallelism:   0,
+		Delay:         10 * time.Second,
+		FailureAction: "rollback",
+		Monitor:       5 * time.Second,
+		Order:         "start-first",
+	}
+	if web.UpdateConfig != wantUpdate {
+		t.Fatalf("unexpected update config: %+v", web.UpdateConfig)
+	}
+	wantDefault := UpdatePolicy{Parallelism: 1, FailureAction: "pause", Monitor: 5 * time.Second, Order: "stop-first"}
+	if web.RollbackConfig != wantDefault {
+		t.Fatalf("expected default rollback config, got %+v", web.RollbackConfig)
+	}
+	if want := (RestartPolicy{Condition: "on-failure", Delay: 5 * time.Second, MaxAttempts: 3}); web.RestartPolicy != want {
+		t.Fatalf("unexpected restart policy: %+v", web.RestartPolicy)
+	}
+	if want := (RestartPolicy{Condition: "on-failure", Delay: 5 * time.Second, MaxAttempts: 5}); state.Services["worker"].RestartPolicy != want {
+		t.Fatalf("expected restart to map to a policy, got %+v", state.Services["worker"].RestartPolicy)
+	}
+}
+
+func TestParseDesiredState_Healthcheck(t *testing.T) {
+	composeYAML := `
+services:
+  web:
+    image: nginx:1.27
+    healthcheck:
+      test: curl -f http://localhost/
+      interval: 1m
+      timeout: 5s
+      retries: 3
+      start_period: 30s
+  worker:
+    image: busybox:latest
+    healthcheck:
+      disable: true
+  cron:
+    image: busybox:latest
+`
+
+	state, err := ParseDesiredState(context.Background(), []byte(composeYAML))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := &Healthcheck{
+		Test:        []string{"CMD-SHELL", "curl -f http://localhost/"},
+		Interval:    time.Minute,
+		Timeout:     5 * time.Second,
+		StartPeriod: 30 * time.Second,
+		Retries:     3,
+	}
+	if got := state.Services["web"].Healthcheck; !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected healthcheck: %+v", got)
+	}
+	if got := state.Services["worker"].Healthcheck; got == nil || !reflect.DeepEqual(got.Test, []string{"NONE"}) {
+		t.Fatalf("expected disabled healthcheck, got %+v", got)
+	}
+	if got := state.Services["cron"].Healthcheck; got != nil {
+		t.Fatalf("expected no healthcheck, got %+v", got)
+	}
+
+	_, err = ParseDesiredState(context.Background(), []byte(`
+services:
+  web:
+    image: nginx:1.27
+    healthcheck:
+      test: ["CMD", "true"]
+      disable: true
+`))
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Service != "web" || perr.Field != "healthcheck" {
+		t.Fatalf("expected healthcheck parse error, got %v", err)
+	}
+}
+
+func TestParseDesiredStateFiles_Mounts(t *testing.T) {
+	body := []byte(`
+services:
+  db:
+    image: postgres:16
+    volumes:
+      - data:/var/lib/postgresql/data
+      - ./init:/docker-entrypoint-initdb.d:ro
+      - /cache
+      - shared:/shared
+      - type: tmpfs
+        target: /tmp
+volumes:
+  data:
+    driver: local
+    driver_opts:
+      type: nfs
+  shared:
+    external: true
+`)
+
+	state, err := ParseDesiredStateFiles(context.Background(), []File{{Name: "compose.yml", Body: body}}, WithStackName("prod"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Mount{
+		{Type: "volume", Target: "/cache"},
+		{Type: "bind", Source: "init", Target: "/docker-entrypoint-initdb.d", ReadOnly: true},
+		{Type: "volume", Source: "shared", Target: "/shared"},
+		{Type: "tmpfs", Target: "/tmp"},
+		{Type: "volume", Source: "prod_data", Target: "/var/lib/postgresql/data", Driver: "local", DriverOptions: map[string]string{"type": "nfs"}},
+	}
+	if got := state.Services["db"].Mounts; !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected mounts: %+v", got)
+	}
+}
+
+func TestParseDesiredState_Runtime(t *testing.T) {
+	composeYAML := `
+services:
+  worker:
+    image: busybox:latest
+    entrypoint: ["/bin/sh", "-c"]
+    command: echo "hello world"
+    user: "1000:1000"
+    working_dir: /app
+    hostname: worker-1
+    stop_signal: SIGINT
+    stop_grace_period: 1m30s
+`
+
+	state, err := ParseDesiredState(context.Background(), []byte(composeYAML))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	worker := state.Services["worker"]
+	if !reflect.DeepEqual(worker.Command, []string{"echo", "hello world"}) {
+		t.Fatalf("unexpected command: %q", worker.Command)
+	}
+	if !reflect.DeepEqual(worker.Entrypoint, []string{"/bin/sh", "-c"}) {
+		t.Fatalf("unexpected entrypoint: %q", worker.Entrypoint)
+	}
+	if worker.User != "1000:1000" || worker.WorkingDir != "/app" || worker.Hostname != "worker-1" {
+		t.Fatalf("unexpected user, working dir or hostname: %+v", worker)
+	}
+	if worker.StopSignal != "SIGINT" || worker.StopGracePeriod != 90*time.Second {
+		t.Fatalf("unexpected stop settings: %s %s", worker.StopSignal, worker.StopGracePeriod)
+	}
+}
+
 func TestParseDesiredState_MissingImage(t *testing.T) {
 	composeYAML := `
 services:
@@ -136,3 +579,101 @@ func TestParseDesiredState_NoServices(t *testing.T) {
 		t.Fatalf("expected no services error, got %v", err)
 	}
 }
+
+func TestParseDesiredStateFiles_MergesInOrder(t *testing.T) {
+	base := `
+services:
+  web:
+    image: nginx:1.27
+    deploy:
+      replicas: 1
+  worker:
+    image: busybox:latest
+`
+	prod := `
+services:
+  web:
+    image: nginx:1.27-alpine
+    deploy:
+      replicas: 4
+`
+
+	state, err := ParseDesiredStateFiles(context.Background(), []File{
+		{Name: "base.yml", Body: []byte(base)},
+		{Name: "prod.yml", Body: []byte(prod)},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	web := state.Services["web"]
+	if web.Image != "nginx:1.27-alpine" || web.Replicas != 4 {
+		t.Fatalf("expected overlay to override web, got %+v", web)
+	}
+	if _, ok := state.Services["worker"]; !ok {
+		t.Fatalf("expected worker from base file")
+	}
+
+	_, err = ParseDesiredStateFiles(context.Background(), []File{
+		{Name: "base.yml", Body: []byte(base)},
+		{Name: "prod.yml"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "prod.yml") {
+		t.Fatalf("expected empty overlay error naming the file, got %v", err)
+	}
+}
+
+func TestParseDesiredStateFiles_ParseErrorLocation(t *testing.T) {
+	base := File{Name: "base.yml", Body: []byte("services:\n  web:\n    image: nginx:1.27\n  worker:\n    image: busybox\n")}
+	tests := []struct {
+		name  string
+		files []File
+		opts  []ParseOption
+		want  ParseError
+	}{
+		{
+			name:  "yaml syntax",
+			files: []File{base, {Name: "prod.yml", Body: []byte("services: [\n")}},
+			want:  ParseError{File: "prod.yml", Line: 1, Message: "did not find expected node content"},
+		},
+		{
+			name:  "schema type",
+			files: []File{base, {Name: "prod.yml", Body: []byte("services:\n  web:\n    ports: 80\n")}},
+			want:  ParseError{File: "prod.yml", Line: 3, Column: 5, Service: "web", Field: "ports"},
+		},
+		{
+			name:  "unknown property",
+			files: []File{base, {Name: "prod.yml", Body: []byte("services:\n  web:\n    replica: 2\n")}},
+			want:  ParseError{File: "prod.yml", Line: 3, Column: 5, Service: "web", Field: "replica"},
+		},
+		{
+			name:  "missing image",
+			files: []File{base, {Name: "prod.yml", Body: []byte("services:\n  api:\n    build: .\n")}},
+			want:  ParseError{File: "prod.yml", Line: 2, Column: 3, Service: "api", Field: "image", Message: "missing image"},
+		},
+		{
+			name:  "unset variable",
+			files: []File{base, {Name: "prod.yml", Body: []byte("services:\n  worker:\n    image: busybox:${TAG}\n")}},
+			opts:  []ParseOption{WithEnvironment(nil)},
+			want:  ParseError{File: "prod.yml", Line: 3, Column: 5, Service: "worker", Field: "image", Message: `variable "TAG" is not set`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseDesiredStateFiles(context.Background(), tt.files, tt.opts...)
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("expected ParseError, got %v", err)
+			}
+			got := *perr
+			got.err = nil
+			if tt.want.Message == "" {
+				tt.want.Message = got.Message
+			}
+			if got != tt.want {
+				t.Fatalf("unexpected parse error:\n got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package compose
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// Swarm defaults applied when a policy, or a field of it, is not set. They
+// match what the daemon uses for a service spec without the setting.
+const (
+	defaultUpdateParallelism  = 1
+	defaultUpdateFailure      = "pause"
+	defaultUpdateMonitor      = 5 * time.Second
+	defaultUpdateOrder        = "stop-first"
+	defaultRestartCondition   = "any"
+	defaultRestartDelay       = 5 * time.Second
+	restartConditionNone      = "none"
+	restartConditionOnFailure = "on-failure"
+)
+
+// UpdatePolicy holds deploy.update_config or deploy.rollback_config with
+// Swarm's defaults filled in for unset fields. Parallelism 0 updates every
+// task at once.
+type UpdatePolicy struct {
+	Parallelism     uint64
+	Delay           time.Duration
+	FailureAction   string
+	Monitor         time.Duration
+	MaxFailureRatio float32
+	Order           string
+}
+
+// RestartPolicy holds deploy.restart_policy with Swarm's defaults filled in.
+// MaxAttempts and Window are 0 when unlimited.
+type RestartPolicy struct {
+	Condition   string
+	Delay       time.Duration
+	MaxAttempts uint64
+	Window      time.Duration
+}
+
+func resolveUpdatePolicy(config *types.UpdateConfig) UpdatePolicy {
+	policy := UpdatePolicy{
+		Parallelism:   defaultUpdateParallelism,
+		FailureAction: defaultUpdateFailure,
+		Monitor:       defaultUpdateMonitor,
+		Order:         defaultUpdateOrder,
+	}
+	if config == nil {
+		return policy
+	}
+	if config.Parallelism != nil {
+		policy.Parallelism = *config.Parallelism
+	}
+	policy.Delay = time.Duration(config.Delay)
+	if config.FailureAction != "" {
+		policy.FailureAction = config.FailureAction
+	}
+	if config.Monitor != 0 {
+		policy.Monitor = time.Duration(config.Monitor)
+	}
+	policy.MaxFailureRatio = config.MaxFailureRatio
+	if config.Order != "" {
+		policy.Order = config.Order
+	}
+	return policy
+}
+
+// resolveRestartPolicy reads deploy.restart_policy, falling back to the
+// service's restart setting as `docker stack deploy` does.
+func resolveRestartPolicy(service types.ServiceConfig) RestartPolicy {
+	policy := RestartPolicy{
+		Condition: defaultRestartCondition,
+		Delay:     defaultRestartDelay,
+	}
+	var config *types.RestartPolicy
+	if service.Deploy != nil {
+		config = service.Deploy.RestartPolicy
+	}
+	if config == nil {
+		restart, attempts, _ := strings.Cut(service.Restart, ":")
+		switch restart {
+		case "no":
+			policy.Condition = restartConditionNone
+		case "on-failure":
+			policy.Condition = restartConditionOnFailure
+			if parsed, err := strconv.ParseUint(attempts, 10, 64); err == nil {
+				policy.MaxAttempts = parsed
+			}
+		}
+		return policy
+	}
+	if config.Condition != "" {
+		policy.Condition = config.Condition
+	}
+	if config.Delay != nil {
+		policy.Delay = time.Duration(*config.Delay)
+	}
+	if config.MaxAttempts != nil {
+		policy.MaxAttempts = *config.MaxAttempts
+	}
+	if config.Window != nil {
+		policy.Window = time.Duration(*config.Window)
+	}
+	return policy
+}
@@ -0,0 +1,280 @@
+package compose
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultS3Region  = "us-east-1"
+	s3Service        = "s3"
+	sigV4Algorithm   = "AWS4-HMAC-SHA256"
+	sigV4TimeFormat  = "20060102T150405Z"
+	sigV4DateFormat  = "20060102"
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	amzContentSHA256 = "X-Amz-Content-Sha256"
+	amzDate          = "X-Amz-Date"
+	amzSecurityToken = "X-Amz-Security-Token"
+)
+
+// S3Config describes how to reach an S3-compatible object store.
+// Endpoint overrides the AWS endpoint (e.g., for MinIO) and always uses
+// path-style addressing; ForcePathStyle only affects AWS endpoints.
+// When AccessKeyID is empty, requests are sent unsigned (public buckets).
+type S3Config struct {
+	Endpoint        string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	ForcePathStyle  bool
+}
+
+// S3Fetcher retrieves a compose file from an S3-compatible bucket using
+// SigV4-signed GET requests. The object ETag is used for conditional requests.
+type S3Fetcher struct {
+	http *HTTPFetcher
+}
+
+// NewS3Fetcher constructs an S3Fetcher for an s3://bucket/key URL.
+func NewS3Fetcher(composeURL string, timeout time.Duration, maxBytes int64, cfg S3Config, opts ...HTTPFetcherOption) (*S3Fetcher, error) {
+	bucket, key, err := parseS3URL(composeURL)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Region == "" {
+		cfg.Region = defaultS3Region
+	}
+	if cfg.AccessKeyID != "" && cfg.SecretAccessKey == "" {
+		return nil, errors.New("s3 secret access key is required when access key id is set")
+	}
+
+	objectURL, err := s3ObjectURL(bucket, key, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	signer := &sigV4Signer{
+		accessKeyID:     cfg.AccessKeyID,
+		secretAccessKey: cfg.SecretAccessKey,
+		sessionToken:    cfg.SessionToken,
+		region:          cfg.Region,
+		service:         s3Service,
+		now:             time.Now,
+	}
+
+	httpOpts := append([]HTTPFetcherOption{}, opts...)
+	httpOpts = append(httpOpts, withRequestPreparer(signer.sign))
+	fetcher, err := NewHTTPFetcher(objectURL, timeout, maxBytes, httpOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &S3Fetcher{http: fetcher}, nil
+}
+
+// Fetch downloads the object, using the previous ETag for conditional requests.
+func (f *S3Fetcher) Fetch(ctx context.Context, previousETag string) (FetchResult, error) {
+	return f.http.Fetch(ctx, previousETag)
+}
+
+func parseS3URL(composeURL string) (string, string, error) {
+	if strings.TrimSpace(composeURL) == "" {
+		return "", "", errors.New("compose url must not be empty")
+	}
+	parsed, err := url.Parse(composeURL)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid compose url: %w", err)
+	}
+	if parsed.Scheme != "s3" {
+		return "", "", errors.New("compose url must use s3 scheme")
+	}
+	if parsed.Host == "" {
+		return "", "", errors.New("s3 compose url must include a bucket")
+	}
+	key := strings.TrimPrefix(parsed.Path, "/")
+	if key == "" {
+		return "", "", errors.New("s3 compose url must include an object key")
+	}
+	return parsed.Host, key, nil
+}
+
+// s3ObjectURL builds the HTTP(S) URL for an object. Custom endpoints (MinIO and
+// most S3-compatible stores) use path-style addressing; AWS uses virtual-hosted
+// style unless path style is forced.
+func s3ObjectURL(bucket, key string, cfg S3Config) (string, error) {
+	escapedKey := s3EscapePath(key)
+	if cfg.Endpoint == "" {
+		if cfg.ForcePathStyle || strings.Contains(bucket, ".") {
+			return fmt.Sprintf("https://s3.%s.amazonaws.com/%s/%s", cfg.Region, bucket, escapedKey), nil
+		}
+		return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", bucket, cfg.Region, escapedKey), nil
+	}
+
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid s3 endpoint: %w", err)
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return "", errors.New("s3 endpoint must use http or https scheme")
+	}
+	if endpoint.Host == "" {
+		return "", errors.New("s3 endpoint must include a host")
+	}
+	base := strings.TrimSuffix(endpoint.Scheme+"://"+endpoint.Host+endpoint.Path, "/")
+	return fmt.Sprintf("%s/%s/%s", base, bucket, escapedKey), nil
+}
+
+// s3EscapePath URI-encodes each path segment as required by SigV4 for S3.
+func s3EscapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = sigV4Escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func sigV4Escape(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// sigV4Signer signs requests with AWS Signature Version 4.
+// Only bodiless requests are supported, which is all the fetcher issues.
+type sigV4Signer struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+	region          string
+	service         string
+	now             func() time.Time
+}
+
+func (s *sigV4Signer) sign(req *http.Request) error {
+	if s.accessKeyID == "" {
+		return nil
+	}
+
+	now := s.now().UTC()
+	timestamp := now.Format(sigV4TimeFormat)
+	date := now.Format(sigV4DateFormat)
+
+	req.Header.Set(amzDate, timestamp)
+	req.Header.Set(amzContentSHA256, emptyPayloadHash)
+	if s.sessionToken != "" {
+		req.Header.Set(amzSecurityToken, s.sessionToken)
+	}
+
+	canonicalHeaders, signedHeaders := sigV4CanonicalHeaders(req)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		sigV4CanonicalQuery(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		emptyPayloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, s.region, s.service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		sigV4Algorithm,
+		timestamp,
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretAccessKey), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, s.service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, s.accessKeyID, scope, signedHeaders, signature))
+	return nil
+}
+
+// sigV4CanonicalHeaders signs host, range and all x-amz-* headers.
+func sigV4CanonicalHeaders(req *http.Request) (string, string) {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	values := map[string]string{"host": host}
+	for name, headerValues := range req.Header {
+		lower := strings.ToLower(name)
+		if lower != "range" && !strings.HasPrefix(lower, "x-amz-") {
+			continue
+		}
+		trimmed := make([]string, 0, len(headerValues))
+		for _, value := range headerValues {
+			trimmed = append(trimmed, strings.Join(strings.Fields(value), " "))
+		}
+		values[lower] = strings.Join(trimmed, ",")
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonical strings.Builder
+	for _, name := range names {
+		canonical.WriteString(name)
+		canonical.WriteByte(':')
+		canonical.WriteString(values[name])
+		canonical.WriteByte('\n')
+	}
+	return canonical.String(), strings.Join(names, ";")
+}
+
+func sigV4CanonicalQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, sigV4Escape(key)+"="+sigV4Escape(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,262 @@
+package compose
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	defaultSignatureMaxBytes int64 = 64 << 10
+	minisignUntrustedPrefix        = "untrusted comment:"
+	minisignTrustedPrefix          = "trusted comment:"
+)
+
+// Verifier checks a fetched compose body before it is parsed.
+type Verifier interface {
+	Verify(ctx context.Context, body []byte) error
+}
+
+// SignatureError reports that a compose body could not be verified against its
+// detached signature. It is distinct from transient errors fetching the
+// signature, which are returned unwrapped.
+type SignatureError struct {
+	URL string
+	Err error
+}
+
+func (e *SignatureError) Error() string {
+	return fmt.Sprintf("compose signature verification failed (%s): %v", RedactURL(e.URL), e.Err)
+}
+
+func (e *SignatureError) Unwrap() error {
+	return e.Err
+}
+
+// PublicKey is a trusted signing key. Supported formats are PEM-encoded PKIX
+// ed25519 and ECDSA keys (cosign-style blob signatures) and minisign public keys.
+type PublicKey struct {
+	ed25519    ed25519.PublicKey
+	ecdsa      *ecdsa.PublicKey
+	minisignID []byte
+}
+
+// ParsePublicKey parses a PEM or minisign public key.
+func ParsePublicKey(data []byte) (PublicKey, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return PublicKey{}, fmt.Errorf("parse public key: %w", err)
+		}
+		switch key := parsed.(type) {
+		case ed25519.PublicKey:
+			return PublicKey{ed25519: key}, nil
+		case *ecdsa.PublicKey:
+			return PublicKey{ecdsa: key}, nil
+		default:
+			return PublicKey{}, fmt.Errorf("unsupported public key type %T", parsed)
+		}
+	}
+
+	encoded := lastLine(data, minisignUntrustedPrefix)
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return PublicKey{}, errors.New("public key is neither PEM nor minisign format")
+	}
+	if len(raw) != 2+8+ed25519.PublicKeySize || string(raw[:2]) != "Ed" {
+		return PublicKey{}, errors.New("invalid minisign public key")
+	}
+	return PublicKey{
+		ed25519:    ed25519.PublicKey(raw[10:]),
+		minisignID: raw[2:10],
+	}, nil
+}
+
+// LoadPublicKeys reads and parses public key files.
+func LoadPublicKeys(paths []string) ([]PublicKey, error) {
+	keys := make([]PublicKey, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read public key: %w", err)
+		}
+		key, err := ParsePublicKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// SignatureVerifier fetches a detached signature for each new compose body and
+// checks it against a set of trusted public keys.
+type SignatureVerifier struct {
+	url     string
+	remote  bool
+	fetcher Fetcher
+	keys    []PublicKey
+}
+
+// NewSignatureVerifier constructs a verifier that reads signatures from
+// signatureURL using the same source options as the compose fetcher.
+func NewSignatureVerifier(signatureURL string, keys []PublicKey, timeout time.Duration, opts ...SourceOption) (*SignatureVerifier, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("signature verification requires at least one public key")
+	}
+	fetcher, err := NewFetcher(signatureURL, timeout, defaultSignatureMaxBytes, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("signature source: %w", err)
+	}
+	_, isFile := fetcher.(*FileFetcher)
+	_, isGit := fetcher.(*GitFetcher)
+	return &SignatureVerifier{url: signatureURL, remote: !isFile && !isGit, fetcher: fetcher, keys: keys}, nil
+}
+
+// SignatureURL derives the default signature location for a compose URL by
+// appending ".sig" to the file path (the path query parameter for git sources).
+func SignatureURL(composeURL string) (string, error) {
+	parsed, err := url.Parse(composeURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid compose url: %w", err)
+	}
+	if parsed.Scheme == "git+file" {
+		query := parsed.Query()
+		query.Set("path", query.Get("path")+".sig")
+		parsed.RawQuery = query.Encode()
+		return parsed.String(), nil
+	}
+	parsed.Path += ".sig"
+	if parsed.RawPath != "" {
+		parsed.RawPath += ".sig"
+	}
+	return parsed.String(), nil
+}
+
+// Verify fetches the current signature and checks it against body. Network
+// errors and 5xx responses from remote sources are returned as plain errors
+// since they say nothing about the compose body; everything else, including a
+// missing signature, is a *SignatureError.
+func (v *SignatureVerifier) Verify(ctx context.Context, body []byte) error {
+	result, err := v.fetcher.Fetch(ctx, "")
+	if err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		var fetchErr *FetchError
+		if errors.As(err, &fetchErr) && fetchErr.StatusCode == http.StatusNotFound {
+			return &SignatureError{URL: v.url, Err: errors.New("signature not found")}
+		}
+		if v.remote && errors.As(err, &fetchErr) && fetchErr.IsRetryable() {
+			return fmt.Errorf("fetch signature: %w", err)
+		}
+		return &SignatureError{URL: v.url, Err: err}
+	}
+	if err := verifySignature(body, result.Body, v.keys); err != nil {
+		return &SignatureError{URL: v.url, Err: err}
+	}
+	return nil
+}
+
+// verifySignature checks a minisign signature file or a base64 blob signature.
+func verifySignature(body, signature []byte, keys []PublicKey) error {
+	if bytes.HasPrefix(bytes.TrimSpace(signature), []byte(minisignUntrustedPrefix)) {
+		return verifyMinisign(body, signature, keys)
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return errors.New("signature is not base64 encoded")
+	}
+	digest := sha256.Sum256(body)
+	for _, key := range keys {
+		switch {
+		case key.minisignID != nil:
+			continue
+		case key.ed25519 != nil:
+			if ed25519.Verify(key.ed25519, body, raw) {
+				return nil
+			}
+		case key.ecdsa != nil:
+			if ecdsa.VerifyASN1(key.ecdsa, digest[:], raw) {
+				return nil
+			}
+		}
+	}
+	return errors.New("signature does not match any trusted key")
+}
+
+// verifyMinisign checks both the signature over the body and the global
+// signature over the trusted comment.
+func verifyMinisign(body, signature []byte, keys []PublicKey) error {
+	lines := strings.Split(strings.TrimSpace(string(signature)), "\n")
+	if len(lines) != 4 {
+		return errors.New("malformed minisign signature")
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(sig) != 2+8+ed25519.SignatureSize {
+		return errors.New("malformed minisign signature")
+	}
+	trusted := strings.TrimRight(lines[2], "\r")
+	if !strings.HasPrefix(trusted, minisignTrustedPrefix) {
+		return errors.New("malformed minisign trusted comment")
+	}
+	trustedComment := strings.TrimPrefix(strings.TrimPrefix(trusted, minisignTrustedPrefix), " ")
+	globalSig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil || len(globalSig) != ed25519.SignatureSize {
+		return errors.New("malformed minisign global signature")
+	}
+
+	message := body
+	switch string(sig[:2]) {
+	case "Ed":
+	case "ED":
+		digest := blake2b.Sum512(body)
+		message = digest[:]
+	default:
+		return fmt.Errorf("unsupported minisign algorithm %q", sig[:2])
+	}
+
+	keyID := sig[2:10]
+	for _, key := range keys {
+		if key.minisignID == nil || !bytes.Equal(key.minisignID, keyID) {
+			continue
+		}
+		if !ed25519.Verify(key.ed25519, message, sig[10:]) {
+			return errors.New("minisign signature does not match")
+		}
+		signed := append(append([]byte{}, sig[10:]...), trustedComment...)
+		if !ed25519.Verify(key.ed25519, signed, globalSig) {
+			return errors.New("minisign trusted comment signature does not match")
+		}
+		return nil
+	}
+	return errors.New("signature key id does not match any trusted key")
+}
+
+// lastLine returns the last non-comment line of a key file.
+func lastLine(data []byte, commentPrefix string) string {
+	var line string
+	for _, candidate := range strings.Split(string(data), "\n") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "" || strings.HasPrefix(candidate, commentPrefix) {
+			continue
+		}
+		line = candidate
+	}
+	return line
+}
@@ -0,0 +1,235 @@
+package compose
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+var signedCompose = []byte("services:\n  web:\n    image: nginx:1.27\n")
+
+func pemPublicKey(t *testing.T, pub any) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatalf("marshal public key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+// minisignFixture builds a minisign public key and a prehashed signature for body.
+func minisignFixture(t *testing.T, body []byte) ([]byte, []byte) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	keyID := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+
+	publicKey := append(append([]byte("Ed"), keyID...), pub...)
+	publicFile := fmt.Sprintf("untrusted comment: minisign public key 0807060504030201\n%s\n",
+		base64.StdEncoding.EncodeToString(publicKey))
+
+	digest := blake2b.Sum512(body)
+	sig := ed25519.Sign(priv, digest[:])
+	trustedComment := "timestamp:1700000000\tfile:compose.yml"
+	globalSig := ed25519.Sign(priv, append(append([]byte{}, sig...), trustedComment...))
+	signature := append(append([]byte("ED"), keyID...), sig...)
+	signatureFile := fmt.Sprintf("untrusted comment: signature from minisign secret key\n%s\ntrusted comment: %s\n%s\n",
+		base64.StdEncoding.EncodeToString(signature),
+		trustedComment,
+		base64.StdEncoding.EncodeToString(globalSig))
+
+	return []byte(publicFile), []byte(signatureFile)
+}
+
+func TestVerifySignature(t *testing.T) {
+	edPub, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ed25519 key: %v", err)
+	}
+	edKey, err := ParsePublicKey(pemPublicKey(t, edPub))
+	if err != nil {
+		t.Fatalf("parse ed25519 key: %v", err)
+	}
+	edSig := base64.StdEncoding.EncodeToString(ed25519.Sign(edPriv, signedCompose))
+
+	ecPriv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ecdsa key: %v", err)
+	}
+	ecKey, err := ParsePublicKey(pemPublicKey(t, &ecPriv.PublicKey))
+	if err != nil {
+		t.Fatalf("parse ecdsa key: %v", err)
+	}
+	digest := sha256.Sum256(signedCompose)
+	ecRaw, err := ecdsa.SignASN1(rand.Reader, ecPriv, digest[:])
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	ecSig := base64.StdEncoding.EncodeToString(ecRaw)
+
+	minisignPub, minisignSig := minisignFixture(t, signedCompose)
+	minisignKey, err := ParsePublicKey(minisignPub)
+	if err != nil {
+		t.Fatalf("parse minisign key: %v", err)
+	}
+	tamperedMinisign := strings.Replace(string(minisignSig), "file:compose.yml", "file:other.yml", 1)
+
+	tests := []struct {
+		name      string
+		body      []byte
+		signature string
+		keys      []PublicKey
+		wantErr   string
+	}{
+		{name: "ed25519", body: signedCompose, signature: edSig, keys: []PublicKey{edKey}},
+		{name: "ecdsa blob signature", body: signedCompose, signature: ecSig + "\n", keys: []PublicKey{edKey, ecKey}},
+		{name: "minisign", body: signedCompose, signature: string(minisignSig), keys: []PublicKey{edKey, minisignKey}},
+		{name: "tampered body", body: []byte("services: {}\n"), signature: edSig, keys: []PublicKey{edKey}, wantErr: "does not match any trusted key"},
+		{name: "untrusted key", body: signedCompose, signature: ecSig, keys: []PublicKey{edKey}, wantErr: "does not match any trusted key"},
+		{name: "not base64", body: signedCompose, signature: "???", keys: []PublicKey{edKey}, wantErr: "not base64"},
+		{name: "minisign tampered body", body: []byte("services: {}\n"), signature: string(minisignSig), keys: []PublicKey{minisignKey}, wantErr: "minisign signature does not match"},
+		{name: "minisign tampered comment", body: signedCompose, signature: tamperedMinisign, keys: []PublicKey{minisignKey}, wantErr: "trusted comment signature"},
+		{name: "minisign unknown key id", body: signedCompose, signature: string(minisignSig), keys: []PublicKey{edKey}, wantErr: "key id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifySignature(tt.body, []byte(tt.signature), tt.keys)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestParsePublicKey_Invalid(t *testing.T) {
+	tests := []string{
+		"",
+		"not a key",
+		"untrusted comment: x\n" + base64.StdEncoding.EncodeToString([]byte("short")),
+	}
+	for _, data := range tests {
+		if _, err := ParsePublicKey([]byte(data)); err == nil {
+			t.Fatalf("expected error for %q", data)
+		}
+	}
+}
+
+func TestSignatureURL(t *testing.T) {
+	tests := []struct {
+		composeURL string
+		want       string
+	}{
+		{composeURL: "https://example.com/prod/compose.yml?ref=main", want: "https://example.com/prod/compose.yml.sig?ref=main"},
+		{composeURL: "file:///srv/compose.yml", want: "file:///srv/compose.yml.sig"},
+		{composeURL: "s3://bucket/prod/compose.yml", want: "s3://bucket/prod/compose.yml.sig"},
+		{composeURL: "git+file:///srv/repo?path=prod%2Fcompose.yml&ref=v1", want: "git+file:///srv/repo?path=prod%2Fcompose.yml.sig&ref=v1"},
+	}
+	for _, tt := range tests {
+		got, err := SignatureURL(tt.composeURL)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != tt.want {
+			t.Fatalf("SignatureURL(%q) = %q, want %q", tt.composeURL, got, tt.want)
+		}
+	}
+}
+
+func TestSignatureVerifier_Verify(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	key, err := ParsePublicKey(pemPublicKey(t, pub))
+	if err != nil {
+		t.Fatalf("parse key: %v", err)
+	}
+
+	t.Run("file signature", func(t *testing.T) {
+		dir := t.TempDir()
+		sigPath := filepath.Join(dir, "compose.yml.sig")
+		sig := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, signedCompose))
+		if err := os.WriteFile(sigPath, []byte(sig), 0o600); err != nil {
+			t.Fatalf("write signature: %v", err)
+		}
+
+		verifier, err := NewSignatureVerifier("file://"+sigPath, []PublicKey{key}, time.Second)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := verifier.Verify(context.Background(), signedCompose); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		err = verifier.Verify(context.Background(), []byte("services: {}\n"))
+		var sigErr *SignatureError
+		if !errors.As(err, &sigErr) {
+			t.Fatalf("expected SignatureError, got %v", err)
+		}
+	})
+
+	t.Run("missing signature", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+
+		verifier, err := NewSignatureVerifier(server.URL+"/compose.yml.sig", []PublicKey{key}, time.Second)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		err = verifier.Verify(context.Background(), signedCompose)
+		var sigErr *SignatureError
+		if !errors.As(err, &sigErr) || !strings.Contains(err.Error(), "signature not found") {
+			t.Fatalf("expected signature not found error, got %v", err)
+		}
+	})
+
+	t.Run("server error is not a verification failure", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		verifier, err := NewSignatureVerifier(server.URL+"/compose.yml.sig", []PublicKey{key}, time.Second)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		verifier.fetcher.(*HTTPFetcher).maxRetries = 0
+		err = verifier.Verify(context.Background(), signedCompose)
+		var sigErr *SignatureError
+		if err == nil || errors.As(err, &sigErr) {
+			t.Fatalf("expected transient fetch error, got %v", err)
+		}
+	})
+
+	t.Run("requires keys", func(t *testing.T) {
+		if _, err := NewSignatureVerifier("file:///srv/compose.yml.sig", nil, time.Second); err == nil {
+			t.Fatal("expected error without keys")
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package compose
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// SourceOption configures fetchers created by NewFetcher.
+type SourceOption func(*sourceOptions)
+
+type sourceOptions struct {
+	s3      S3Config
+	auth    *HTTPAuth
+	headers map[string]string
+	tls     *TLSConfig
+}
+
+// WithS3Config sets the object store settings used for s3:// compose URLs.
+func WithS3Config(cfg S3Config) SourceOption {
+	return func(o *sourceOptions) {
+		o.s3 = cfg
+	}
+}
+
+// WithHTTPAuth authenticates http(s) compose requests.
+func WithHTTPAuth(auth HTTPAuth) SourceOption {
+	return func(o *sourceOptions) {
+		o.auth = &auth
+	}
+}
+
+// WithHTTPHeaders adds custom headers to http(s) compose requests.
+func WithHTTPHeaders(headers map[string]string) SourceOption {
+	return func(o *sourceOptions) {
+		o.headers = headers
+	}
+}
+
+// WithTLS sets the client TLS settings for http(s) and s3 compose requests.
+func WithTLS(cfg TLSConfig) SourceOption {
+	return func(o *sourceOptions) {
+		if cfg.IsZero() {
+			o.tls = nil
+			return
+		}
+		o.tls = &cfg
+	}
+}
+
+// NewFetcher selects a Fetcher implementation based on the compose URL scheme.
+// http and https URLs use HTTPFetcher, file URLs use FileFetcher, git+file
+// URLs use GitFetcher and s3 URLs use S3Fetcher.
+func NewFetcher(composeURL string, timeout time.Duration, maxBytes int64, opts ...SourceOption) (Fetcher, error) {
+	parsed, err := url.Parse(composeURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid compose url: %w", err)
+	}
+
+	options := sourceOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	isHTTP := parsed.Scheme == "http" || parsed.Scheme == "https"
+	if !isHTTP && (options.auth != nil || len(options.headers) > 0) {
+		return nil, fmt.Errorf("auth and headers are only supported for http(s) compose urls, not %q", parsed.Scheme)
+	}
+
+	var tlsOpts []HTTPFetcherOption
+	if options.tls != nil {
+		if !isHTTP && parsed.Scheme != "s3" {
+			return nil, fmt.Errorf("tls settings are only supported for http(s) and s3 compose urls, not %q", parsed.Scheme)
+		}
+		tlsConfig, err := options.tls.ClientConfig()
+		if err != nil {
+			return nil, err
+		}
+		tlsOpts = append(tlsOpts, WithTLSClientConfig(tlsConfig))
+	}
+
+	switch parsed.Scheme {
+	case "http", "https":
+		httpOpts := tlsOpts
+		if options.auth != nil {
+			if err := options.auth.Validate(); err != nil {
+				return nil, err
+			}
+			httpOpts = append(httpOpts, WithAuth(*options.auth))
+		}
+		if len(options.headers) > 0 {
+			httpOpts = append(httpOpts, WithHeaders(options.headers))
+		}
+		return NewHTTPFetcher(composeURL, timeout, maxBytes, httpOpts...)
+	case "file":
+		return NewFileFetcher(composeURL, maxBytes)
+	case "git+file":
+		return NewGitFetcher(composeURL, timeout, maxBytes)
+	case "s3":
+		return NewS3Fetcher(composeURL, timeout, maxBytes, options.s3, tlsOpts...)
+	default:
+		return nil, fmt.Errorf("unsupported compose url scheme %q", parsed.Scheme)
+	}
+}
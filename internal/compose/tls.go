@@ -0,0 +1,92 @@
+package compose
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/docker/go-connections/tlsconfig"
+)
+
+// TLSConfig describes the client TLS settings used to reach a compose source.
+// CAFile is added to the system roots rather than replacing them, so public
+// endpoints keep working alongside a private CA. MinVersion accepts "1.2" or
+// "1.3" (TLS 1.2 is the default floor); ServerName overrides the name used for
+// SNI and certificate verification.
+type TLSConfig struct {
+	CAFile     string
+	CertFile   string
+	KeyFile    string
+	MinVersion string
+	ServerName string
+}
+
+// IsZero reports whether no TLS settings are configured.
+func (c TLSConfig) IsZero() bool {
+	return c == TLSConfig{}
+}
+
+// Validate reports whether the settings are consistent. Files are only read
+// when the client configuration is built.
+func (c TLSConfig) Validate() error {
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return errors.New("tls client cert and key must be set together")
+	}
+	if _, err := ParseTLSVersion(c.MinVersion); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ClientConfig loads the CA bundle and client key pair and returns the
+// resulting *tls.Config.
+func (c TLSConfig) ClientConfig() (*tls.Config, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	minVersion, _ := ParseTLSVersion(c.MinVersion)
+	cfg, err := tlsconfig.Client(tlsconfig.Options{
+		CAFile:     c.CAFile,
+		CertFile:   c.CertFile,
+		KeyFile:    c.KeyFile,
+		MinVersion: minVersion,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("compose tls: %w", err)
+	}
+	cfg.ServerName = c.ServerName
+	return cfg, nil
+}
+
+// ParseTLSVersion converts "1.2" or "1.3" to the crypto/tls constant.
+// An empty value returns 0, meaning the library default.
+func ParseTLSVersion(value string) (uint16, error) {
+	switch value {
+	case "":
+		return 0, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported tls min version %q (expected 1.2 or 1.3)", value)
+	}
+}
+
+// WithTLSClientConfig sets the TLS configuration used for compose requests.
+// The default transport is cloned so proxy and timeout settings are kept.
+func WithTLSClientConfig(cfg *tls.Config) HTTPFetcherOption {
+	return func(f *HTTPFetcher) {
+		if cfg == nil {
+			return
+		}
+		transport, ok := http.DefaultTransport.(*http.Transport)
+		if !ok {
+			return
+		}
+		cloned := transport.Clone()
+		cloned.TLSClientConfig = cfg
+		f.client.Transport = cloned
+	}
+}
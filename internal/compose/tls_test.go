@@ -0,0 +1,176 @@
+package compose
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writePEM writes a single PEM block to dir/name and returns the path.
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+// newClientCert generates a self-signed client certificate and returns the
+// parsed certificate along with the cert and key file paths.
+func newClientCert(t *testing.T, dir string) (*x509.Certificate, string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "swarm-sentinel"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	certPath := writePEM(t, dir, "client.pem", "CERTIFICATE", der)
+	keyPath := writePEM(t, dir, "client-key.pem", "EC PRIVATE KEY", keyDER)
+	return cert, certPath, keyPath
+}
+
+func newMTLSServer(t *testing.T, clientCA *x509.Certificate) *httptest.Server {
+	t.Helper()
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("services: {}\n"))
+	}))
+	pool := x509.NewCertPool()
+	pool.AddCert(clientCA)
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  pool,
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNewFetcher_TLS(t *testing.T) {
+	dir := t.TempDir()
+	clientCert, certFile, keyFile := newClientCert(t, dir)
+	server := newMTLSServer(t, clientCert)
+	caFile := writePEM(t, dir, "ca.pem", "CERTIFICATE", server.Certificate().Raw)
+
+	tests := []struct {
+		name    string
+		tls     TLSConfig
+		wantErr string
+	}{
+		{
+			name: "ca and client cert",
+			tls:  TLSConfig{CAFile: caFile, CertFile: certFile, KeyFile: keyFile, MinVersion: "1.2"},
+		},
+		{
+			name: "server name override",
+			tls:  TLSConfig{CAFile: caFile, CertFile: certFile, KeyFile: keyFile, ServerName: "example.com"},
+		},
+		{
+			name:    "unknown authority",
+			tls:     TLSConfig{CertFile: certFile, KeyFile: keyFile},
+			wantErr: "certificate",
+		},
+		{
+			name:    "missing client cert",
+			tls:     TLSConfig{CAFile: caFile},
+			wantErr: "compose fetch failed",
+		},
+		{
+			name:    "server name mismatch",
+			tls:     TLSConfig{CAFile: caFile, CertFile: certFile, KeyFile: keyFile, ServerName: "artifacts.invalid"},
+			wantErr: "artifacts.invalid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher, err := NewFetcher(server.URL+"/compose.yml", time.Second, 0, WithTLS(tt.tls))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			httpFetcher, ok := fetcher.(*HTTPFetcher)
+			if !ok {
+				t.Fatalf("expected *HTTPFetcher, got %T", fetcher)
+			}
+			httpFetcher.maxRetries = 0
+
+			result, err := fetcher.Fetch(context.Background(), "")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if string(result.Body) != "services: {}\n" {
+					t.Fatalf("unexpected body: %q", string(result.Body))
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNewFetcher_TLSRejected(t *testing.T) {
+	dir := t.TempDir()
+	_, certFile, keyFile := newClientCert(t, dir)
+
+	tests := []struct {
+		name    string
+		url     string
+		tls     TLSConfig
+		wantErr string
+	}{
+		{name: "file source", url: "file:///srv/compose.yml", tls: TLSConfig{ServerName: "example.com"}, wantErr: "only supported"},
+		{name: "cert without key", url: "https://example.com/compose.yml", tls: TLSConfig{CertFile: certFile}, wantErr: "set together"},
+		{name: "unsupported version", url: "https://example.com/compose.yml", tls: TLSConfig{MinVersion: "1.0"}, wantErr: "unsupported tls min version"},
+		{name: "missing ca file", url: "https://example.com/compose.yml", tls: TLSConfig{CAFile: filepath.Join(dir, "missing.pem")}, wantErr: "compose tls"},
+		{name: "mismatched key", url: "s3://bucket/compose.yml", tls: TLSConfig{CertFile: keyFile, KeyFile: certFile}, wantErr: "compose tls"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewFetcher(tt.url, time.Second, 0, WithTLS(tt.tls))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
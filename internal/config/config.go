@@ -29,14 +29,41 @@ const (
 	envLogLevel           = "SS_LOG_LEVEL"
 	envStatePath          = "SS_STATE_PATH"
 	envAlertStabilization = "SS_ALERT_STABILIZATION_CYCLES"
+	envSourceFailures     = "SS_SOURCE_FAILURE_THRESHOLD"
+	envSourceMaxAge       = "SS_SOURCE_MAX_AGE"
+	envLabelPrefixes      = "SS_LABEL_PREFIXES"
+	envPolicySeverity     = "SS_POLICY_DRIFT_SEVERITY"
 	envHealthPort         = "SS_HEALTH_PORT"
 	envMetricsPort        = "SS_METRICS_PORT"
 	envWebhookURL         = "SS_WEBHOOK_URL"
 	envWebhookTemplate    = "SS_WEBHOOK_TEMPLATE"
 	envDryRun             = "SS_DRY_RUN"
+	envS3Endpoint         = "SS_S3_ENDPOINT"
+	envS3Region           = "SS_S3_REGION"
+	envS3AccessKeyID      = "SS_S3_ACCESS_KEY_ID"
+	envS3SecretAccessKey  = "SS_S3_SECRET_ACCESS_KEY"
+	envS3SessionToken     = "SS_S3_SESSION_TOKEN"
+	envS3ForcePathStyle   = "SS_S3_FORCE_PATH_STYLE"
+	envComposeAuthType    = "SS_COMPOSE_AUTH_TYPE"
+	envComposeAuthUser    = "SS_COMPOSE_AUTH_USERNAME"
+	envComposeAuthPass    = "SS_COMPOSE_AUTH_PASSWORD"
+	envComposeAuthHeader  = "SS_COMPOSE_AUTH_HEADER"
+	envComposeAuthToken   = "SS_COMPOSE_AUTH_TOKEN"
+	envComposeTLSCA       = "SS_COMPOSE_TLS_CA"
+	envComposeTLSCert     = "SS_COMPOSE_TLS_CERT"
+	envComposeTLSKey      = "SS_COMPOSE_TLS_KEY"
+	envComposeTLSMinVer   = "SS_COMPOSE_TLS_MIN_VERSION"
+	envComposeTLSServer   = "SS_COMPOSE_TLS_SERVER_NAME"
+	envComposeSigKeys     = "SS_COMPOSE_SIGNATURE_KEYS"
+	envComposeSigURL      = "SS_COMPOSE_SIGNATURE_URL"
+	envComposeEnvFile     = "SS_COMPOSE_ENV_FILE"
 
 	envDockerTLSVerifyCompat = "DOCKER_TLS_VERIFY"
 	envDockerCertPathCompat  = "DOCKER_CERT_PATH"
+	envAWSRegionCompat       = "AWS_REGION"
+	envAWSAccessKeyIDCompat  = "AWS_ACCESS_KEY_ID"
+	envAWSSecretKeyCompat    = "AWS_SECRET_ACCESS_KEY"
+	envAWSSessionTokenCompat = "AWS_SESSION_TOKEN"
 )
 
 const (
@@ -53,6 +80,7 @@ const (
 	defaultLogLevel                 = "info"
 	defaultStatePath                = "/var/lib/swarm-sentinel/state.json"
 	defaultAlertStabilizationCycles = 2
+	defaultSourceFailureThreshold   = 3
 	defaultHealthPort               = 8080
 	defaultMetricsPort              = 9090
 )
@@ -63,6 +91,10 @@ type Config struct {
 	ComposeTimeout           time.Duration
 	DockerAPITimeout         time.Duration
 	ComposeURL               string
+	ComposeAuth              *AuthConfig
+	ComposeTLS               TLSConfig
+	ComposeSignature         *SignatureConfig
+	ComposeEnvFile           string
 	SlackWebhookURL          string
 	WebhookURL               string
 	WebhookTemplate          string
@@ -76,9 +108,19 @@ type Config struct {
 	LogLevel                 string
 	StatePath                string
 	AlertStabilizationCycles int
+	SourceFailureThreshold   int
+	SourceMaxAge             time.Duration
+	LabelPrefixes            []string
+	PolicyDriftSeverity      string
 	HealthPort               int
 	MetricsPort              int
 	DryRun                   bool
+	S3Endpoint               string
+	S3Region                 string
+	S3AccessKeyID            string
+	S3SecretAccessKey        string
+	S3SessionToken           string
+	S3ForcePathStyle         bool
 }
 
 // Load reads configuration from environment variables and a local .env file if present.
@@ -96,6 +138,7 @@ func Load() (Config, error) {
 		LogLevel:                 defaultLogLevel,
 		StatePath:                defaultStatePath,
 		AlertStabilizationCycles: defaultAlertStabilizationCycles,
+		SourceFailureThreshold:   defaultSourceFailureThreshold,
 		HealthPort:               defaultHealthPort,
 		MetricsPort:              defaultMetricsPort,
 	}
@@ -112,7 +155,7 @@ func Load() (Config, error) {
 	}
 
 	if value, ok := lookupTrimmed(envComposeURL); ok {
-		cfg.ComposeURL = value
+		cfg.ComposeURL = composeSourceURL(value)
 	}
 
 	if value, ok := lookupTrimmed(envComposeTimeout); ok {
@@ -126,6 +169,48 @@ func Load() (Config, error) {
 		cfg.ComposeTimeout = timeout
 	}
 
+	if value, ok := lookupTrimmed(envComposeAuthType); ok && value != "" {
+		auth := &AuthConfig{Type: strings.ToLower(value)}
+		if username, ok := lookupTrimmed(envComposeAuthUser); ok {
+			auth.Username = username
+		}
+		if header, ok := lookupTrimmed(envComposeAuthHeader); ok {
+			auth.Header = header
+		}
+		auth.Password = loadSecretFromFile(envComposeAuthPass)
+		auth.Token = loadSecretFromFile(envComposeAuthToken)
+		cfg.ComposeAuth = auth
+	}
+
+	if value, ok := lookupTrimmed(envComposeTLSCA); ok {
+		cfg.ComposeTLS.CAFile = value
+	}
+	if value, ok := lookupTrimmed(envComposeTLSCert); ok {
+		cfg.ComposeTLS.CertFile = value
+	}
+	if value, ok := lookupTrimmed(envComposeTLSKey); ok {
+		cfg.ComposeTLS.KeyFile = value
+	}
+	if value, ok := lookupTrimmed(envComposeTLSMinVer); ok {
+		cfg.ComposeTLS.MinVersion = value
+	}
+	if value, ok := lookupTrimmed(envComposeTLSServer); ok {
+		cfg.ComposeTLS.ServerName = value
+	}
+
+	if value, ok := lookupTrimmed(envComposeSigKeys); ok && value != "" {
+		cfg.ComposeSignature = &SignatureConfig{PublicKeys: splitList(value)}
+	}
+	if value, ok := lookupTrimmed(envComposeSigURL); ok && value != "" {
+		if cfg.ComposeSignature == nil {
+			cfg.ComposeSignature = &SignatureConfig{}
+		}
+		cfg.ComposeSignature.URL = value
+	}
+	if value, ok := lookupTrimmed(envComposeEnvFile); ok {
+		cfg.ComposeEnvFile = value
+	}
+
 	// Use _FILE pattern for sensitive URLs (Docker/K8s secrets support)
 	cfg.SlackWebhookURL = loadSecretFromFile(envSlackWebhookURL)
 	cfg.WebhookURL = loadSecretFromFile(envWebhookURL)
@@ -171,6 +256,38 @@ func Load() (Config, error) {
 		}
 		cfg.AlertStabilizationCycles = parsed
 	}
+	if value, ok := lookupTrimmed(envSourceFailures); ok {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s: %w", envSourceFailures, err)
+		}
+		if parsed < 0 {
+			return Config{}, fmt.Errorf("%s cannot be negative", envSourceFailures)
+		}
+		cfg.SourceFailureThreshold = parsed
+	}
+	if value, ok := lookupTrimmed(envSourceMaxAge); ok {
+		maxAge, err := time.ParseDuration(value)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s: %w", envSourceMaxAge, err)
+		}
+		if maxAge < 0 {
+			return Config{}, fmt.Errorf("%s cannot be negative", envSourceMaxAge)
+		}
+		cfg.SourceMaxAge = maxAge
+	}
+	if value, ok := lookupTrimmed(envLabelPrefixes); ok {
+		cfg.LabelPrefixes = splitList(value)
+	}
+	if value, ok := lookupTrimmed(envPolicySeverity); ok && value != "" {
+		value = strings.ToLower(value)
+		switch value {
+		case "ok", "degraded", "failed":
+			cfg.PolicyDriftSeverity = value
+		default:
+			return Config{}, fmt.Errorf("invalid %s: expected ok, degraded or failed", envPolicySeverity)
+		}
+	}
 	if value, ok := lookupTrimmed(envHealthPort); ok {
 		parsed, err := strconv.Atoi(value)
 		if err != nil {
@@ -197,6 +314,31 @@ func Load() (Config, error) {
 		cfg.DryRun = dryRun
 	}
 
+	if value, ok := lookupTrimmed(envS3Endpoint); ok {
+		cfg.S3Endpoint = value
+	}
+	if value, ok := lookupTrimmed(envS3Region); ok {
+		cfg.S3Region = value
+	} else if value, ok := lookupTrimmed(envAWSRegionCompat); ok {
+		cfg.S3Region = value
+	}
+	// S3 credentials support the _FILE pattern and fall back to the standard AWS variables.
+	cfg.S3AccessKeyID = loadSecretFromFile(envS3AccessKeyID)
+	cfg.S3SecretAccessKey = loadSecretFromFile(envS3SecretAccessKey)
+	cfg.S3SessionToken = loadSecretFromFile(envS3SessionToken)
+	if cfg.S3AccessKeyID == "" && cfg.S3SecretAccessKey == "" {
+		cfg.S3AccessKeyID = loadSecretFromFile(envAWSAccessKeyIDCompat)
+		cfg.S3SecretAccessKey = loadSecretFromFile(envAWSSecretKeyCompat)
+		if cfg.S3SessionToken == "" {
+			cfg.S3SessionToken = loadSecretFromFile(envAWSSessionTokenCompat)
+		}
+	}
+	if forcePathStyle, set, err := lookupBool(envS3ForcePathStyle); err != nil {
+		return Config{}, err
+	} else if set {
+		cfg.S3ForcePathStyle = forcePathStyle
+	}
+
 	tlsVerify, tlsVerifySet, err := lookupBool(envDockerTLSVerify)
 	if err != nil {
 		return Config{}, err
@@ -252,7 +394,57 @@ func Load() (Config, error) {
 	}
 
 	if cfg.ComposeURL != "" {
-		if err := validateHTTPURL(cfg.ComposeURL, "SS_COMPOSE_URL"); err != nil {
+		if err := validateComposeURL(cfg.ComposeURL, "SS_COMPOSE_URL"); err != nil {
+			return Config{}, err
+		}
+	}
+
+	if cfg.ComposeAuth != nil {
+		if mappingPath != "" {
+			return Config{}, fmt.Errorf("%s is only supported in single-stack mode; use auth blocks in the mapping file", envComposeAuthType)
+		}
+		if !isHTTPComposeURL(cfg.ComposeURL) {
+			return Config{}, fmt.Errorf("%s requires an http(s) SS_COMPOSE_URL", envComposeAuthType)
+		}
+		if err := cfg.ComposeAuth.validate(false); err != nil {
+			return Config{}, fmt.Errorf("invalid %s: %w", envComposeAuthType, err)
+		}
+		if (cfg.ComposeAuth.Type == "bearer" || cfg.ComposeAuth.Type == "header") && cfg.ComposeAuth.Token == "" {
+			return Config{}, fmt.Errorf("%s=%s requires %s or %s_FILE", envComposeAuthType, cfg.ComposeAuth.Type, envComposeAuthToken, envComposeAuthToken)
+		}
+		if cfg.ComposeAuth.Type == "basic" && cfg.ComposeAuth.Password == "" {
+			return Config{}, fmt.Errorf("%s=basic requires %s or %s_FILE", envComposeAuthType, envComposeAuthPass, envComposeAuthPass)
+		}
+	}
+
+	if (cfg.ComposeTLS.CertFile == "") != (cfg.ComposeTLS.KeyFile == "") {
+		return Config{}, fmt.Errorf("%s and %s must be set together", envComposeTLSCert, envComposeTLSKey)
+	}
+	switch cfg.ComposeTLS.MinVersion {
+	case "", "1.2", "1.3":
+	default:
+		return Config{}, fmt.Errorf("invalid %s: expected 1.2 or 1.3", envComposeTLSMinVer)
+	}
+
+	if cfg.ComposeSignature != nil {
+		if len(cfg.ComposeSignature.PublicKeys) == 0 {
+			return Config{}, fmt.Errorf("%s requires %s", envComposeSigURL, envComposeSigKeys)
+		}
+		if cfg.ComposeSignature.URL != "" {
+			if mappingPath != "" {
+				return Config{}, fmt.Errorf("%s is only supported in single-stack mode; use signature blocks in the mapping file", envComposeSigURL)
+			}
+			if err := validateComposeURL(cfg.ComposeSignature.URL, envComposeSigURL); err != nil {
+				return Config{}, err
+			}
+		}
+	}
+
+	if cfg.ComposeEnvFile != "" {
+		if mappingPath != "" {
+			return Config{}, fmt.Errorf("%s is only supported in single-stack mode; use env_file in the mapping file", envComposeEnvFile)
+		}
+		if err := validateEnvFile(cfg.ComposeEnvFile, envComposeEnvFile); err != nil {
 			return Config{}, err
 		}
 	}
@@ -282,6 +474,15 @@ func Load() (Config, error) {
 		return Config{}, errors.New("https docker host requires TLS configuration")
 	}
 
+	if cfg.S3Endpoint != "" {
+		if err := validateHTTPURL(cfg.S3Endpoint, envS3Endpoint); err != nil {
+			return Config{}, err
+		}
+	}
+	if (cfg.S3AccessKeyID == "") != (cfg.S3SecretAccessKey == "") {
+		return Config{}, fmt.Errorf("%s and %s must be set together", envS3AccessKeyID, envS3SecretAccessKey)
+	}
+
 	if cfg.SlackWebhookURL != "" {
 		if err := validateURL(cfg.SlackWebhookURL, "SS_SLACK_WEBHOOK_URL"); err != nil {
 			return Config{}, err
@@ -312,6 +513,17 @@ func loadSecretFromFile(envVar string) string {
 	return strings.TrimSpace(os.Getenv(envVar))
 }
 
+// splitList splits a comma-separated value, dropping empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func lookupTrimmed(key string) (string, bool) {
 	value, ok := os.LookupEnv(key)
 	if !ok {
@@ -357,6 +569,99 @@ func validateURL(value, name string) error {
 	return nil
 }
 
+// validateComposeURL accepts http(s) compose sources as well as file:// URLs
+// pointing at a local compose file or directory, git+file:// URLs pointing at
+// a local git repository (see composeSourceURL for plain clone paths) and
+// s3://bucket/key object URLs.
+func validateComposeURL(value, name string) error {
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	switch parsed.Scheme {
+	case "file":
+		return validateFileURL(parsed, name)
+	case "git+file":
+		if err := validateFileURL(parsed, name); err != nil {
+			return err
+		}
+		if strings.TrimSpace(parsed.Query().Get("path")) == "" {
+			return fmt.Errorf("invalid %s: git URL requires a path query parameter", name)
+		}
+		return nil
+	case "s3":
+		if parsed.Host == "" {
+			return fmt.Errorf("invalid %s: s3 URL must include a bucket", name)
+		}
+		if strings.TrimPrefix(parsed.Path, "/") == "" {
+			return fmt.Errorf("invalid %s: s3 URL must include an object key", name)
+		}
+		return nil
+	case "http", "https", "":
+		return validateHTTPURL(value, name)
+	default:
+		return fmt.Errorf("invalid %s: must be http, https, file, git+file or s3 URL", name)
+	}
+}
+
+// validateEnvFile accepts an absolute local path or any compose source URL.
+func validateEnvFile(value, name string) error {
+	if filepath.IsAbs(value) {
+		return nil
+	}
+	if !strings.Contains(value, "://") {
+		return fmt.Errorf("invalid %s: must be an absolute path or a compose source URL", name)
+	}
+	return validateComposeURL(value, name)
+}
+
+// EnvFileURL converts an env file setting into a compose source URL; absolute
+// paths become file:// URLs.
+func EnvFileURL(value string) string {
+	if filepath.IsAbs(value) {
+		return (&url.URL{Scheme: "file", Path: filepath.ToSlash(value)}).String()
+	}
+	return value
+}
+
+// composeSourceURL converts a compose source setting into a URL. An absolute
+// path names a local git clone and becomes a git+file:// URL, keeping its ref
+// and path query, e.g. /srv/deploy?ref=main&path=prod/compose.yml.
+func composeSourceURL(value string) string {
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Scheme != "" || !filepath.IsAbs(filepath.FromSlash(parsed.Path)) {
+		return value
+	}
+	parsed.Scheme = "git+file"
+	return parsed.String()
+}
+
+func isHTTPComposeURL(value string) bool {
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return false
+	}
+	return parsed.Scheme == "http" || parsed.Scheme == "https"
+}
+
+func isS3ComposeURL(value string) bool {
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return false
+	}
+	return parsed.Scheme == "s3"
+}
+
+func validateFileURL(u *url.URL, name string) error {
+	if u.Host != "" && u.Host != "localhost" {
+		return fmt.Errorf("invalid %s: file URL must not specify a remote host", name)
+	}
+	if u.Path == "" || !strings.HasPrefix(u.Path, "/") {
+		return fmt.Errorf("invalid %s: file URL must use an absolute path", name)
+	}
+	return nil
+}
+
 func validateHTTPURL(value, name string) error {
 	parsed, err := url.Parse(value)
 	if err != nil {
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -37,6 +38,7 @@ func TestLoad_ValidationAndDefaults(t *testing.T) {
 				LogLevel:                 defaultLogLevel,
 				StatePath:                defaultStatePath,
 				AlertStabilizationCycles: defaultAlertStabilizationCycles,
+				SourceFailureThreshold:   defaultSourceFailureThreshold,
 				HealthPort:               defaultHealthPort,
 				MetricsPort:              defaultMetricsPort,
 				DryRun:                   false,
@@ -57,6 +59,7 @@ func TestLoad_ValidationAndDefaults(t *testing.T) {
 				LogLevel:                 defaultLogLevel,
 				StatePath:                defaultStatePath,
 				AlertStabilizationCycles: defaultAlertStabilizationCycles,
+				SourceFailureThreshold:   defaultSourceFailureThreshold,
 				HealthPort:               defaultHealthPort,
 				MetricsPort:              defaultMetricsPort,
 				DryRun:                   false,
@@ -122,6 +125,20 @@ func TestLoad_ValidationAndDefaults(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid compose file url relative path",
+			env: map[string]string{
+				envComposeURL: "file:compose.yml",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid compose file url remote host",
+			env: map[string]string{
+				envComposeURL: "file://fileserver/compose.yml",
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid docker proxy url",
 			env: map[string]string{
@@ -157,6 +174,7 @@ func TestLoad_ValidationAndDefaults(t *testing.T) {
 				LogLevel:                 defaultLogLevel,
 				StatePath:                defaultStatePath,
 				AlertStabilizationCycles: defaultAlertStabilizationCycles,
+				SourceFailureThreshold:   defaultSourceFailureThreshold,
 				HealthPort:               defaultHealthPort,
 				MetricsPort:              defaultMetricsPort,
 				DryRun:                   false,
@@ -181,11 +199,31 @@ func TestLoad_ValidationAndDefaults(t *testing.T) {
 				LogLevel:                 defaultLogLevel,
 				StatePath:                defaultStatePath,
 				AlertStabilizationCycles: defaultAlertStabilizationCycles,
+				SourceFailureThreshold:   defaultSourceFailureThreshold,
 				HealthPort:               defaultHealthPort,
 				MetricsPort:              defaultMetricsPort,
 				DryRun:                   false,
 			},
 		},
+		{
+			name: "file compose url",
+			env: map[string]string{
+				envComposeURL: "file:///srv/compose/stack.yml",
+			},
+			want: Config{
+				PollInterval:             defaultPollInterval,
+				ComposeTimeout:           defaultComposeTimeout,
+				DockerAPITimeout:         defaultDockerAPITimeout,
+				ComposeURL:               "file:///srv/compose/stack.yml",
+				DockerProxyURL:           defaultDockerProxyURL,
+				LogLevel:                 defaultLogLevel,
+				StatePath:                defaultStatePath,
+				AlertStabilizationCycles: defaultAlertStabilizationCycles,
+				SourceFailureThreshold:   defaultSourceFailureThreshold,
+				HealthPort:               defaultHealthPort,
+				MetricsPort:              defaultMetricsPort,
+			},
+		},
 		{
 			name: "stack name set",
 			env: map[string]string{
@@ -204,6 +242,7 @@ func TestLoad_ValidationAndDefaults(t *testing.T) {
 				LogLevel:                 defaultLogLevel,
 				StatePath:                defaultStatePath,
 				AlertStabilizationCycles: defaultAlertStabilizationCycles,
+				SourceFailureThreshold:   defaultSourceFailureThreshold,
 				HealthPort:               defaultHealthPort,
 				MetricsPort:              defaultMetricsPort,
 				DryRun:                   false,
@@ -241,6 +280,7 @@ func TestLoad_ValidationAndDefaults(t *testing.T) {
 				LogLevel:                 defaultLogLevel,
 				StatePath:                defaultStatePath,
 				AlertStabilizationCycles: defaultAlertStabilizationCycles,
+				SourceFailureThreshold:   defaultSourceFailureThreshold,
 				HealthPort:               defaultHealthPort,
 				MetricsPort:              defaultMetricsPort,
 				DryRun:                   false,
@@ -268,6 +308,7 @@ func TestLoad_ValidationAndDefaults(t *testing.T) {
 				LogLevel:                 defaultLogLevel,
 				StatePath:                defaultStatePath,
 				AlertStabilizationCycles: defaultAlertStabilizationCycles,
+				SourceFailureThreshold:   defaultSourceFailureThreshold,
 				HealthPort:               defaultHealthPort,
 				MetricsPort:              defaultMetricsPort,
 				DryRun:                   false,
@@ -294,6 +335,7 @@ func TestLoad_ValidationAndDefaults(t *testing.T) {
 				LogLevel:                 defaultLogLevel,
 				StatePath:                defaultStatePath,
 				AlertStabilizationCycles: defaultAlertStabilizationCycles,
+				SourceFailureThreshold:   defaultSourceFailureThreshold,
 				HealthPort:               defaultHealthPort,
 				MetricsPort:              defaultMetricsPort,
 				DryRun:                   false,
@@ -317,6 +359,7 @@ func TestLoad_ValidationAndDefaults(t *testing.T) {
 				LogLevel:                 defaultLogLevel,
 				StatePath:                defaultStatePath,
 				AlertStabilizationCycles: defaultAlertStabilizationCycles,
+				SourceFailureThreshold:   defaultSourceFailureThreshold,
 				HealthPort:               defaultHealthPort,
 				MetricsPort:              defaultMetricsPort,
 				DryRun:                   false,
@@ -356,6 +399,7 @@ func TestLoad_ValidationAndDefaults(t *testing.T) {
 				LogLevel:                 "debug",
 				StatePath:                defaultStatePath,
 				AlertStabilizationCycles: defaultAlertStabilizationCycles,
+				SourceFailureThreshold:   defaultSourceFailureThreshold,
 				HealthPort:               defaultHealthPort,
 				MetricsPort:              defaultMetricsPort,
 				DryRun:                   false,
@@ -392,7 +436,7 @@ func TestLoad_ValidationAndDefaults(t *testing.T) {
 				t.Fatalf("unexpected error: %v", err)
 			}
 
-			if got != tc.want {
+			if !reflect.DeepEqual(got, tc.want) {
 				t.Fatalf("unexpected config: %+v", got)
 			}
 		})
@@ -641,3 +685,396 @@ func TestLoadSecretFromFile(t *testing.T) {
 		}
 	})
 }
+
+func TestLoad_S3Settings(t *testing.T) {
+	t.Run("loads credentials from _FILE", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		restoreDir := mustChdir(t, tmpDir)
+		defer restoreDir()
+
+		secretFile := filepath.Join(tmpDir, "s3-secret")
+		if err := os.WriteFile(secretFile, []byte("minio-secret\n"), 0o600); err != nil {
+			t.Fatalf("write secret file: %v", err)
+		}
+
+		t.Setenv(envComposeURL, "s3://artifacts/prod/compose.yml")
+		t.Setenv(envS3Endpoint, "http://minio:9000")
+		t.Setenv(envS3AccessKeyID, "minio")
+		t.Setenv(envS3SecretAccessKey+"_FILE", secretFile)
+		t.Setenv(envS3ForcePathStyle, "true")
+
+		got, err := Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got.S3Endpoint != "http://minio:9000" || got.S3AccessKeyID != "minio" || got.S3SecretAccessKey != "minio-secret" {
+			t.Fatalf("unexpected s3 settings: %+v", got)
+		}
+		if !got.S3ForcePathStyle {
+			t.Fatalf("expected force path style")
+		}
+	})
+
+	t.Run("falls back to AWS variables", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		restoreDir := mustChdir(t, tmpDir)
+		defer restoreDir()
+
+		t.Setenv(envComposeURL, "s3://artifacts/prod/compose.yml")
+		t.Setenv(envAWSRegionCompat, "eu-west-1")
+		t.Setenv(envAWSAccessKeyIDCompat, "AKIA")
+		t.Setenv(envAWSSecretKeyCompat, "secret")
+
+		got, err := Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got.S3Region != "eu-west-1" || got.S3AccessKeyID != "AKIA" || got.S3SecretAccessKey != "secret" {
+			t.Fatalf("unexpected s3 settings: %+v", got)
+		}
+	})
+
+	t.Run("rejects partial credentials", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		restoreDir := mustChdir(t, tmpDir)
+		defer restoreDir()
+
+		t.Setenv(envComposeURL, "s3://artifacts/prod/compose.yml")
+		t.Setenv(envS3AccessKeyID, "minio")
+
+		if _, err := Load(); err == nil {
+			t.Fatal("expected error for access key without secret")
+		}
+	})
+
+	t.Run("rejects s3 url without key", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		restoreDir := mustChdir(t, tmpDir)
+		defer restoreDir()
+
+		t.Setenv(envComposeURL, "s3://artifacts/")
+
+		if _, err := Load(); err == nil {
+			t.Fatal("expected error for s3 url without object key")
+		}
+	})
+}
+
+func TestLoad_ComposeAuth(t *testing.T) {
+	t.Run("loads token from _FILE", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		restoreDir := mustChdir(t, tmpDir)
+		defer restoreDir()
+
+		tokenFile := filepath.Join(tmpDir, "token")
+		if err := os.WriteFile(tokenFile, []byte("gh-token\n"), 0o600); err != nil {
+			t.Fatalf("write token file: %v", err)
+		}
+
+		t.Setenv(envComposeURL, "https://raw.githubusercontent.com/org/repo/main/compose.yml")
+		t.Setenv(envComposeAuthType, "bearer")
+		t.Setenv(envComposeAuthToken+"_FILE", tokenFile)
+
+		got, err := Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.ComposeAuth == nil || got.ComposeAuth.Type != "bearer" || got.ComposeAuth.Token != "gh-token" {
+			t.Fatalf("unexpected compose auth: %+v", got.ComposeAuth)
+		}
+	})
+
+	t.Run("rejects bearer without token", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		restoreDir := mustChdir(t, tmpDir)
+		defer restoreDir()
+
+		t.Setenv(envComposeURL, "https://example.com/compose.yml")
+		t.Setenv(envComposeAuthType, "bearer")
+
+		if _, err := Load(); err == nil {
+			t.Fatal("expected error for bearer auth without token")
+		}
+	})
+
+	t.Run("rejects basic without password", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		restoreDir := mustChdir(t, tmpDir)
+		defer restoreDir()
+
+		t.Setenv(envComposeURL, "https://example.com/compose.yml")
+		t.Setenv(envComposeAuthType, "basic")
+		t.Setenv(envComposeAuthUser, "deploy")
+
+		if _, err := Load(); err == nil {
+			t.Fatal("expected error for basic auth without password")
+		}
+	})
+
+	t.Run("rejects auth for non-http source", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		restoreDir := mustChdir(t, tmpDir)
+		defer restoreDir()
+
+		t.Setenv(envComposeURL, "file:///srv/compose.yml")
+		t.Setenv(envComposeAuthType, "basic")
+		t.Setenv(envComposeAuthUser, "deploy")
+
+		if _, err := Load(); err == nil {
+			t.Fatal("expected error for auth with file source")
+		}
+	})
+}
+
+func TestLoad_ComposeTLS(t *testing.T) {
+	t.Run("loads tls settings", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		restoreDir := mustChdir(t, tmpDir)
+		defer restoreDir()
+
+		t.Setenv(envComposeURL, "https://artifacts.internal/compose.yml")
+		t.Setenv(envComposeTLSCA, "/certs/ca.pem")
+		t.Setenv(envComposeTLSCert, "/certs/client.pem")
+		t.Setenv(envComposeTLSKey, "/certs/client-key.pem")
+		t.Setenv(envComposeTLSMinVer, "1.3")
+		t.Setenv(envComposeTLSServer, "artifacts.example.com")
+
+		got, err := Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := TLSConfig{
+			CAFile:     "/certs/ca.pem",
+			CertFile:   "/certs/client.pem",
+			KeyFile:    "/certs/client-key.pem",
+			MinVersion: "1.3",
+			ServerName: "artifacts.example.com",
+		}
+		if got.ComposeTLS != want {
+			t.Fatalf("unexpected compose tls: %+v", got.ComposeTLS)
+		}
+	})
+
+	t.Run("rejects cert without key", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		restoreDir := mustChdir(t, tmpDir)
+		defer restoreDir()
+
+		t.Setenv(envComposeURL, "https://example.com/compose.yml")
+		t.Setenv(envComposeTLSCert, "/certs/client.pem")
+
+		if _, err := Load(); err == nil {
+			t.Fatal("expected error for cert without key")
+		}
+	})
+
+	t.Run("rejects unsupported min version", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		restoreDir := mustChdir(t, tmpDir)
+		defer restoreDir()
+
+		t.Setenv(envComposeURL, "https://example.com/compose.yml")
+		t.Setenv(envComposeTLSMinVer, "1.0")
+
+		if _, err := Load(); err == nil {
+			t.Fatal("expected error for unsupported min version")
+		}
+	})
+}
+
+func TestLoad_ComposeSignature(t *testing.T) {
+	t.Run("loads keys and url", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		restoreDir := mustChdir(t, tmpDir)
+		defer restoreDir()
+
+		t.Setenv(envComposeURL, "https://example.com/compose.yml")
+		t.Setenv(envComposeSigKeys, "/keys/release.pub, /keys/backup.pub")
+		t.Setenv(envComposeSigURL, "https://signatures.example.com/compose.yml.sig")
+
+		got, err := Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.ComposeSignature == nil {
+			t.Fatal("expected signature config")
+		}
+		if len(got.ComposeSignature.PublicKeys) != 2 || got.ComposeSignature.PublicKeys[1] != "/keys/backup.pub" {
+			t.Fatalf("unexpected public keys: %v", got.ComposeSignature.PublicKeys)
+		}
+		if got.ComposeSignature.URL != "https://signatures.example.com/compose.yml.sig" {
+			t.Fatalf("unexpected signature url: %q", got.ComposeSignature.URL)
+		}
+	})
+
+	t.Run("rejects url without keys", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		restoreDir := mustChdir(t, tmpDir)
+		defer restoreDir()
+
+		t.Setenv(envComposeURL, "https://example.com/compose.yml")
+		t.Setenv(envComposeSigURL, "https://example.com/compose.yml.sig")
+
+		if _, err := Load(); err == nil {
+			t.Fatal("expected error for signature url without keys")
+		}
+	})
+}
+
+func TestLoad_ComposeEnvFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "absolute path", value: "/run/configs/release.env"},
+		{name: "https url", value: "https://example.com/release.env"},
+		{name: "relative path", value: "release.env", wantErr: true},
+		{name: "unsupported scheme", value: "ftp://example.com/release.env", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			restoreDir := mustChdir(t, tmpDir)
+			defer restoreDir()
+
+			t.Setenv(envComposeURL, "https://example.com/compose.yml")
+			t.Setenv(envComposeEnvFile, tt.value)
+
+			got, err := Load()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error for %q", tt.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.ComposeEnvFile != tt.value {
+				t.Fatalf("unexpected env file: %q", got.ComposeEnvFile)
+			}
+		})
+	}
+}
+
+func TestEnvFileURL(t *testing.T) {
+	if got := EnvFileURL("/run/configs/release.env"); got != "file:///run/configs/release.env" {
+		t.Fatalf("unexpected url for path: %q", got)
+	}
+	if got := EnvFileURL("s3://bucket/release.env"); got != "s3://bucket/release.env" {
+		t.Fatalf("unexpected url passthrough: %q", got)
+	}
+}
+
+func TestLoad_GitClonePath(t *testing.T) {
+	t.Run("converts clone path to git url", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		restoreDir := mustChdir(t, tmpDir)
+		defer restoreDir()
+
+		t.Setenv(envComposeURL, "/srv/deploy-artifacts?ref=main&path=prod/compose.yml")
+
+		got, err := Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.ComposeURL != "git+file:///srv/deploy-artifacts?ref=main&path=prod/compose.yml" {
+			t.Fatalf("unexpected compose url: %q", got.ComposeURL)
+		}
+	})
+
+	t.Run("requires path query", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		restoreDir := mustChdir(t, tmpDir)
+		defer restoreDir()
+
+		t.Setenv(envComposeURL, "/srv/deploy-artifacts")
+
+		if _, err := Load(); err == nil {
+			t.Fatal("expected error for clone path without path query")
+		}
+	})
+}
+
+func TestLoad_SourceAlertThresholds(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		restoreDir := mustChdir(t, t.TempDir())
+		defer restoreDir()
+		t.Setenv(envComposeURL, "https://example.com/compose.yml")
+
+		got, err := Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.SourceFailureThreshold != defaultSourceFailureThreshold || got.SourceMaxAge != 0 {
+			t.Fatalf("unexpected defaults: %d, %s", got.SourceFailureThreshold, got.SourceMaxAge)
+		}
+	})
+
+	t.Run("overrides", func(t *testing.T) {
+		restoreDir := mustChdir(t, t.TempDir())
+		defer restoreDir()
+		t.Setenv(envComposeURL, "https://example.com/compose.yml")
+		t.Setenv(envSourceFailures, "0")
+		t.Setenv(envSourceMaxAge, "72h")
+
+		got, err := Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.SourceFailureThreshold != 0 || got.SourceMaxAge != 72*time.Hour {
+			t.Fatalf("unexpected thresholds: %d, %s", got.SourceFailureThreshold, got.SourceMaxAge)
+		}
+	})
+
+	for key, value := range map[string]string{envSourceFailures: "-1", envSourceMaxAge: "-1h"} {
+		t.Run("rejects negative "+key, func(t *testing.T) {
+			restoreDir := mustChdir(t, t.TempDir())
+			defer restoreDir()
+			t.Setenv(envComposeURL, "https://example.com/compose.yml")
+			t.Setenv(key, value)
+			if _, err := Load(); err == nil {
+				t.Fatalf("expected error for %s=%s", key, value)
+			}
+		})
+	}
+}
+
+func TestLoad_LabelPrefixes(t *testing.T) {
+	restoreDir := mustChdir(t, t.TempDir())
+	defer restoreDir()
+	t.Setenv(envComposeURL, "https://example.com/compose.yml")
+	t.Setenv(envLabelPrefixes, "traefik., ,com.example.")
+
+	got, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got.LabelPrefixes, []string{"traefik.", "com.example."}) {
+		t.Fatalf("unexpected label prefixes: %v", got.LabelPrefixes)
+	}
+}
+
+func TestLoad_PolicyDriftSeverity(t *testing.T) {
+	restoreDir := mustChdir(t, t.TempDir())
+	defer restoreDir()
+	t.Setenv(envComposeURL, "https://example.com/compose.yml")
+	t.Setenv(envPolicySeverity, "Failed")
+
+	got, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.PolicyDriftSeverity != "failed" {
+		t.Fatalf("unexpected policy drift severity: %q", got.PolicyDriftSeverity)
+	}
+
+	t.Setenv(envPolicySeverity, "critical")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for unknown severity")
+	}
+}
@@ -3,20 +3,74 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
-// StackMapping represents a single stack → compose URL mapping.
+// StackMapping represents a single stack → compose URL mapping. ComposeURLs
+// lists several compose files that are merged in order (later files override
+// earlier ones); exactly one of ComposeURL and ComposeURLs is set. EnvFile
+// opts into strict variable interpolation from a local path or source URL.
 type StackMapping struct {
-	Name       string        `yaml:"name"`
-	ComposeURL string        `yaml:"compose_url"`
-	Timeout    time.Duration `yaml:"timeout,omitempty"`
+	Name        string            `yaml:"name"`
+	ComposeURL  string            `yaml:"compose_url,omitempty"`
+	ComposeURLs []string          `yaml:"compose_urls,omitempty"`
+	Timeout     time.Duration     `yaml:"timeout,omitempty"`
+	Auth        *AuthConfig       `yaml:"auth,omitempty"`
+	Headers     map[string]string `yaml:"headers,omitempty"`
+	TLS         *TLSConfig        `yaml:"tls,omitempty"`
+	Signature   *SignatureConfig  `yaml:"signature,omitempty"`
+	EnvFile     string            `yaml:"env_file,omitempty"`
+}
+
+// Sources returns the stack's compose URLs in merge order.
+func (m StackMapping) Sources() []string {
+	if len(m.ComposeURLs) > 0 {
+		return m.ComposeURLs
+	}
+	if m.ComposeURL == "" {
+		return nil
+	}
+	return []string{m.ComposeURL}
+}
+
+// AuthConfig describes credentials for fetching a compose file over HTTP.
+// Secret values are never inlined: they are read from TokenFile/PasswordFile
+// (or _FILE env vars in single-stack mode) into Token/Password.
+type AuthConfig struct {
+	Type         string `yaml:"type"`
+	Username     string `yaml:"username,omitempty"`
+	PasswordFile string `yaml:"password_file,omitempty"`
+	Header       string `yaml:"header,omitempty"`
+	TokenFile    string `yaml:"token_file,omitempty"`
+
+	Password string `yaml:"-"`
+	Token    string `yaml:"-"`
+}
+
+// TLSConfig describes client TLS settings for fetching a compose file over
+// https or from an S3 endpoint. In a mapping file, set fields override the
+// global SS_COMPOSE_TLS_* values for that stack.
+type TLSConfig struct {
+	CAFile     string `yaml:"ca_file,omitempty"`
+	CertFile   string `yaml:"cert_file,omitempty"`
+	KeyFile    string `yaml:"key_file,omitempty"`
+	MinVersion string `yaml:"min_version,omitempty"`
+	ServerName string `yaml:"server_name,omitempty"`
+}
+
+// SignatureConfig enables detached signature verification of compose files.
+// URL defaults to the compose URL with a ".sig" suffix. In a mapping file,
+// PublicKeys falls back to the global SS_COMPOSE_SIGNATURE_KEYS when empty.
+type SignatureConfig struct {
+	PublicKeys []string `yaml:"public_keys,omitempty"`
+	URL        string   `yaml:"url,omitempty"`
 }
 
 // MappingFile is the parsed YAML structure for multi-stack configuration:
-// stacks: [{name, compose_url, timeout}]
+// stacks: [{name, compose_url | compose_urls, timeout, auth, headers, tls, signature, env_file}]
 type MappingFile struct {
 	Stacks []StackMapping `yaml:"stacks"`
 }
@@ -38,10 +92,27 @@ func LoadMappingFile(path string) ([]StackMapping, error) {
 		return nil, fmt.Errorf("parse mapping file: %w", err)
 	}
 
+	for i := range mf.Stacks {
+		stack := &mf.Stacks[i]
+		stack.ComposeURL = composeSourceURL(stack.ComposeURL)
+		for j := range stack.ComposeURLs {
+			stack.ComposeURLs[j] = composeSourceURL(stack.ComposeURLs[j])
+		}
+	}
+
 	if err := validateMappings(mf.Stacks); err != nil {
 		return nil, err
 	}
 
+	for i := range mf.Stacks {
+		if mf.Stacks[i].Auth == nil {
+			continue
+		}
+		if err := mf.Stacks[i].Auth.loadSecrets(); err != nil {
+			return nil, fmt.Errorf("stack %q: auth: %w", mf.Stacks[i].Name, err)
+		}
+	}
+
 	return mf.Stacks, nil
 }
 
@@ -58,12 +129,22 @@ func validateMappings(mappings []StackMapping) error {
 			return fmt.Errorf("stack %d: name is required", i)
 		}
 
-		if m.ComposeURL == "" {
+		if m.ComposeURL != "" && len(m.ComposeURLs) > 0 {
+			return fmt.Errorf("stack %q: compose_url and compose_urls are mutually exclusive", m.Name)
+		}
+		if m.ComposeURL == "" && len(m.ComposeURLs) == 0 {
 			return fmt.Errorf("stack %q: compose_url is required", m.Name)
 		}
 
-		if err := validateHTTPURL(m.ComposeURL, "compose_url"); err != nil {
-			return fmt.Errorf("stack %q: %w", m.Name, err)
+		sources := m.Sources()
+		for j, composeURL := range sources {
+			field := "compose_url"
+			if len(m.ComposeURLs) > 0 {
+				field = fmt.Sprintf("compose_urls[%d]", j)
+			}
+			if err := validateComposeURL(composeURL, field); err != nil {
+				return fmt.Errorf("stack %q: %w", m.Name, err)
+			}
 		}
 
 		if seen[m.Name] {
@@ -74,7 +155,139 @@ func validateMappings(mappings []StackMapping) error {
 		if m.Timeout < 0 {
 			return fmt.Errorf("stack %q: timeout cannot be negative", m.Name)
 		}
+
+		if m.Auth != nil || len(m.Headers) > 0 {
+			for _, composeURL := range sources {
+				if !isHTTPComposeURL(composeURL) {
+					return fmt.Errorf("stack %q: auth and headers require an http(s) compose_url", m.Name)
+				}
+			}
+		}
+		if m.Auth != nil {
+			if err := m.Auth.validate(true); err != nil {
+				return fmt.Errorf("stack %q: auth: %w", m.Name, err)
+			}
+		}
+		if m.Signature != nil && m.Signature.URL != "" {
+			if len(sources) > 1 {
+				return fmt.Errorf("stack %q: signature.url cannot be used with compose_urls", m.Name)
+			}
+			if err := validateComposeURL(m.Signature.URL, "signature.url"); err != nil {
+				return fmt.Errorf("stack %q: %w", m.Name, err)
+			}
+		}
+		if m.EnvFile != "" {
+			if err := validateEnvFile(m.EnvFile, "env_file"); err != nil {
+				return fmt.Errorf("stack %q: %w", m.Name, err)
+			}
+		}
+		if m.TLS != nil {
+			for _, composeURL := range sources {
+				if !isHTTPComposeURL(composeURL) && !isS3ComposeURL(composeURL) {
+					return fmt.Errorf("stack %q: tls requires an https or s3 compose_url", m.Name)
+				}
+			}
+			if err := m.TLS.validate(); err != nil {
+				return fmt.Errorf("stack %q: tls: %w", m.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validate checks that client cert and key are paired and the minimum
+// version is supported.
+func (t TLSConfig) validate() error {
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return fmt.Errorf("cert_file and key_file must be set together")
+	}
+	switch t.MinVersion {
+	case "", "1.2", "1.3":
+	default:
+		return fmt.Errorf("unsupported min_version %q (expected 1.2 or 1.3)", t.MinVersion)
+	}
+	return nil
+}
+
+// Merge returns t with any fields set in override replacing its own.
+func (t TLSConfig) Merge(override *TLSConfig) TLSConfig {
+	if override == nil {
+		return t
+	}
+	if override.CAFile != "" {
+		t.CAFile = override.CAFile
+	}
+	if override.CertFile != "" || override.KeyFile != "" {
+		t.CertFile = override.CertFile
+		t.KeyFile = override.KeyFile
+	}
+	if override.MinVersion != "" {
+		t.MinVersion = override.MinVersion
+	}
+	if override.ServerName != "" {
+		t.ServerName = override.ServerName
+	}
+	return t
+}
+
+// validate checks that the auth block is complete for its type. fromFiles
+// requires secrets to be referenced by file path (mapping file mode).
+func (a *AuthConfig) validate(fromFiles bool) error {
+	switch a.Type {
+	case "bearer":
+		if fromFiles && a.TokenFile == "" {
+			return fmt.Errorf("bearer auth requires token_file")
+		}
+	case "basic":
+		if a.Username == "" {
+			return fmt.Errorf("basic auth requires username")
+		}
+		if fromFiles && a.PasswordFile == "" {
+			return fmt.Errorf("basic auth requires password_file")
+		}
+	case "header":
+		if a.Header == "" {
+			return fmt.Errorf("header auth requires header")
+		}
+		if fromFiles && a.TokenFile == "" {
+			return fmt.Errorf("header auth requires token_file")
+		}
+	case "":
+		return fmt.Errorf("type is required")
+	default:
+		return fmt.Errorf("unsupported type %q (expected bearer, basic or header)", a.Type)
 	}
+	return nil
+}
 
+// loadSecrets reads the token and password files referenced by the auth block.
+func (a *AuthConfig) loadSecrets() error {
+	if a.TokenFile != "" {
+		token, err := readSecretFile(a.TokenFile)
+		if err != nil {
+			return fmt.Errorf("read token_file: %w", err)
+		}
+		a.Token = token
+	}
+	if a.PasswordFile != "" {
+		password, err := readSecretFile(a.PasswordFile)
+		if err != nil {
+			return fmt.Errorf("read password_file: %w", err)
+		}
+		a.Password = password
+	}
 	return nil
 }
+
+func readSecretFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	value := strings.TrimSpace(string(content))
+	if value == "" {
+		return "", fmt.Errorf("%s is empty", path)
+	}
+	return value, nil
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -162,6 +163,58 @@ func TestLoadMappingFile_InvalidScheme(t *testing.T) {
 	}
 }
 
+func TestLoadMappingFile_NonHTTPSchemes(t *testing.T) {
+	tmpDir := t.TempDir()
+	yamlFile := filepath.Join(tmpDir, "schemes.yaml")
+
+	yaml := `stacks:
+  - name: dev
+    compose_url: file:///srv/compose/dev.yml
+  - name: ci
+    compose_url: file:///srv/compose/ci/
+  - name: prod
+    compose_url: git+file:///srv/deploy-artifacts?ref=main&path=prod/compose.yml
+  - name: monitoring
+    compose_url: s3://deploy-artifacts/monitoring/compose.yml
+  - name: staging
+    compose_url: /srv/deploy-artifacts?ref=staging&path=staging/compose.yml
+`
+
+	if err := os.WriteFile(yamlFile, []byte(yaml), 0o600); err != nil {
+		t.Fatalf("write yaml: %v", err)
+	}
+
+	mappings, err := LoadMappingFile(yamlFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mappings) != 5 {
+		t.Fatalf("expected 5 mappings, got %d", len(mappings))
+	}
+	if got := mappings[4].ComposeURL; got != "git+file:///srv/deploy-artifacts?ref=staging&path=staging/compose.yml" {
+		t.Fatalf("expected clone path to become a git url, got %q", got)
+	}
+}
+
+func TestLoadMappingFile_GitSchemeRequiresPath(t *testing.T) {
+	tmpDir := t.TempDir()
+	yamlFile := filepath.Join(tmpDir, "git_no_path.yaml")
+
+	yaml := `stacks:
+  - name: prod
+    compose_url: git+file:///srv/deploy-artifacts?ref=main
+`
+
+	if err := os.WriteFile(yamlFile, []byte(yaml), 0o600); err != nil {
+		t.Fatalf("write yaml: %v", err)
+	}
+
+	_, err := LoadMappingFile(yamlFile)
+	if err == nil {
+		t.Fatal("expected error for git URL without path")
+	}
+}
+
 func TestLoadMappingFile_DuplicateNames(t *testing.T) {
 	tmpDir := t.TempDir()
 	yamlFile := filepath.Join(tmpDir, "dups.yaml")
@@ -202,3 +255,349 @@ func TestLoadMappingFile_NegativeTimeout(t *testing.T) {
 		t.Fatal("expected error for negative timeout")
 	}
 }
+
+func TestLoadMappingFile_AuthFromFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	yamlFile := filepath.Join(tmpDir, "auth.yaml")
+	tokenFile := filepath.Join(tmpDir, "gitlab-token")
+	passwordFile := filepath.Join(tmpDir, "artifacts-password")
+	if err := os.WriteFile(tokenFile, []byte("glpat-123\n"), 0o600); err != nil {
+		t.Fatalf("write token: %v", err)
+	}
+	if err := os.WriteFile(passwordFile, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatalf("write password: %v", err)
+	}
+
+	yaml := `stacks:
+  - name: prod
+    compose_url: https://gitlab.example.com/api/v4/projects/1/jobs/artifacts/main/raw/compose.yml
+    auth:
+      type: header
+      header: PRIVATE-TOKEN
+      token_file: ` + tokenFile + `
+    headers:
+      Accept: application/x-yaml
+  - name: staging
+    compose_url: https://artifacts.example.com/staging/compose.yml
+    auth:
+      type: basic
+      username: deploy
+      password_file: ` + passwordFile + `
+`
+
+	if err := os.WriteFile(yamlFile, []byte(yaml), 0o600); err != nil {
+		t.Fatalf("write yaml: %v", err)
+	}
+
+	mappings, err := LoadMappingFile(yamlFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	prod := mappings[0]
+	if prod.Auth == nil || prod.Auth.Type != "header" || prod.Auth.Header != "PRIVATE-TOKEN" || prod.Auth.Token != "glpat-123" {
+		t.Fatalf("unexpected prod auth: %+v", prod.Auth)
+	}
+	if prod.Headers["Accept"] != "application/x-yaml" {
+		t.Fatalf("unexpected prod headers: %+v", prod.Headers)
+	}
+
+	staging := mappings[1]
+	if staging.Auth == nil || staging.Auth.Username != "deploy" || staging.Auth.Password != "s3cret" {
+		t.Fatalf("unexpected staging auth: %+v", staging.Auth)
+	}
+}
+
+func TestLoadMappingFile_InvalidAuth(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{
+			name: "missing type",
+			yaml: `stacks:
+  - name: prod
+    compose_url: https://example.com/compose.yml
+    auth:
+      token_file: /run/secrets/token
+`,
+		},
+		{
+			name: "bearer without token file",
+			yaml: `stacks:
+  - name: prod
+    compose_url: https://example.com/compose.yml
+    auth:
+      type: bearer
+`,
+		},
+		{
+			name: "basic without password file",
+			yaml: `stacks:
+  - name: prod
+    compose_url: https://example.com/compose.yml
+    auth:
+      type: basic
+      username: deploy
+`,
+		},
+		{
+			name: "token file missing",
+			yaml: `stacks:
+  - name: prod
+    compose_url: https://example.com/compose.yml
+    auth:
+      type: bearer
+      token_file: /nonexistent/token
+`,
+		},
+		{
+			name: "auth on non-http source",
+			yaml: `stacks:
+  - name: prod
+    compose_url: file:///srv/compose.yml
+    headers:
+      X-Test: "1"
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yamlFile := filepath.Join(t.TempDir(), "auth.yaml")
+			if err := os.WriteFile(yamlFile, []byte(tt.yaml), 0o600); err != nil {
+				t.Fatalf("write yaml: %v", err)
+			}
+			if _, err := LoadMappingFile(yamlFile); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestLoadMappingFile_TLS(t *testing.T) {
+	yamlContent := `stacks:
+  - name: internal
+    compose_url: https://artifacts.internal/compose.yml
+    tls:
+      ca_file: /certs/ca.pem
+      cert_file: /certs/client.pem
+      key_file: /certs/client-key.pem
+      min_version: "1.3"
+      server_name: artifacts.example.com
+  - name: minio
+    compose_url: s3://deployments/compose.yml
+    tls:
+      ca_file: /certs/minio-ca.pem
+`
+	yamlFile := filepath.Join(t.TempDir(), "tls.yaml")
+	if err := os.WriteFile(yamlFile, []byte(yamlContent), 0o600); err != nil {
+		t.Fatalf("write yaml: %v", err)
+	}
+
+	mappings, err := LoadMappingFile(yamlFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := TLSConfig{
+		CAFile:     "/certs/ca.pem",
+		CertFile:   "/certs/client.pem",
+		KeyFile:    "/certs/client-key.pem",
+		MinVersion: "1.3",
+		ServerName: "artifacts.example.com",
+	}
+	if mappings[0].TLS == nil || *mappings[0].TLS != want {
+		t.Fatalf("unexpected tls: %+v", mappings[0].TLS)
+	}
+	if mappings[1].TLS == nil || mappings[1].TLS.CAFile != "/certs/minio-ca.pem" {
+		t.Fatalf("unexpected tls: %+v", mappings[1].TLS)
+	}
+}
+
+func TestLoadMappingFile_InvalidTLS(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{
+			name: "cert without key",
+			yaml: `stacks:
+  - name: prod
+    compose_url: https://example.com/compose.yml
+    tls:
+      cert_file: /certs/client.pem
+`,
+		},
+		{
+			name: "unsupported min version",
+			yaml: `stacks:
+  - name: prod
+    compose_url: https://example.com/compose.yml
+    tls:
+      min_version: "1.1"
+`,
+		},
+		{
+			name: "tls on file source",
+			yaml: `stacks:
+  - name: prod
+    compose_url: file:///srv/compose.yml
+    tls:
+      ca_file: /certs/ca.pem
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yamlFile := filepath.Join(t.TempDir(), "tls.yaml")
+			if err := os.WriteFile(yamlFile, []byte(tt.yaml), 0o600); err != nil {
+				t.Fatalf("write yaml: %v", err)
+			}
+			if _, err := LoadMappingFile(yamlFile); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestTLSConfig_Merge(t *testing.T) {
+	global := TLSConfig{
+		CAFile:     "/certs/ca.pem",
+		CertFile:   "/certs/client.pem",
+		KeyFile:    "/certs/client-key.pem",
+		MinVersion: "1.2",
+	}
+
+	if got := global.Merge(nil); got != global {
+		t.Fatalf("expected nil override to keep global settings, got %+v", got)
+	}
+
+	got := global.Merge(&TLSConfig{
+		CertFile:   "/certs/stack.pem",
+		KeyFile:    "/certs/stack-key.pem",
+		ServerName: "artifacts.example.com",
+	})
+	want := TLSConfig{
+		CAFile:     "/certs/ca.pem",
+		CertFile:   "/certs/stack.pem",
+		KeyFile:    "/certs/stack-key.pem",
+		MinVersion: "1.2",
+		ServerName: "artifacts.example.com",
+	}
+	if got != want {
+		t.Fatalf("unexpected merged tls: %+v", got)
+	}
+}
+
+func TestLoadMappingFile_Signature(t *testing.T) {
+	yamlContent := `stacks:
+  - name: prod
+    compose_url: https://example.com/prod/compose.yml
+    signature:
+      public_keys: [/keys/release.pub]
+      url: https://signatures.example.com/prod/compose.yml.sig
+  - name: invalid
+    compose_url: https://example.com/invalid/compose.yml
+    signature:
+      url: ftp://example.com/compose.yml.sig
+`
+	yamlFile := filepath.Join(t.TempDir(), "signature.yaml")
+	if err := os.WriteFile(yamlFile, []byte(yamlContent), 0o600); err != nil {
+		t.Fatalf("write yaml: %v", err)
+	}
+
+	if _, err := LoadMappingFile(yamlFile); err == nil || !strings.Contains(err.Error(), `stack "invalid"`) {
+		t.Fatalf("expected invalid signature url error, got %v", err)
+	}
+
+	valid := strings.SplitN(yamlContent, "  - name: invalid", 2)[0]
+	if err := os.WriteFile(yamlFile, []byte(valid), 0o600); err != nil {
+		t.Fatalf("write yaml: %v", err)
+	}
+	mappings, err := LoadMappingFile(yamlFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	signature := mappings[0].Signature
+	if signature == nil || signature.URL != "https://signatures.example.com/prod/compose.yml.sig" || len(signature.PublicKeys) != 1 {
+		t.Fatalf("unexpected signature config: %+v", signature)
+	}
+}
+
+func TestLoadMappingFile_ComposeURLs(t *testing.T) {
+	valid := `stacks:
+  - name: prod
+    compose_urls:
+      - https://example.com/base.yml
+      - https://example.com/prod.yml
+`
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{
+			name: "both url forms",
+			yaml: `stacks:
+  - name: prod
+    compose_url: https://example.com/base.yml
+    compose_urls: [https://example.com/prod.yml]
+`,
+			wantErr: "mutually exclusive",
+		},
+		{
+			name: "invalid overlay url",
+			yaml: `stacks:
+  - name: prod
+    compose_urls: [https://example.com/base.yml, ftp://example.com/prod.yml]
+`,
+			wantErr: "compose_urls[1]",
+		},
+		{
+			name: "headers on file overlay",
+			yaml: `stacks:
+  - name: prod
+    compose_urls: [https://example.com/base.yml, file:///srv/prod.yml]
+    headers:
+      X-Env: prod
+`,
+			wantErr: "require an http(s) compose_url",
+		},
+		{
+			name: "explicit signature url",
+			yaml: `stacks:
+  - name: prod
+    compose_urls: [https://example.com/base.yml, https://example.com/prod.yml]
+    signature:
+      public_keys: [/keys/release.pub]
+      url: https://example.com/compose.yml.sig
+`,
+			wantErr: "signature.url",
+		},
+	}
+
+	yamlFile := filepath.Join(t.TempDir(), "overlays.yaml")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(yamlFile, []byte(tt.yaml), 0o600); err != nil {
+				t.Fatalf("write yaml: %v", err)
+			}
+			if _, err := LoadMappingFile(yamlFile); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	if err := os.WriteFile(yamlFile, []byte(valid), 0o600); err != nil {
+		t.Fatalf("write yaml: %v", err)
+	}
+	mappings, err := LoadMappingFile(yamlFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sources := mappings[0].Sources()
+	if len(sources) != 2 || sources[1] != "https://example.com/prod.yml" {
+		t.Fatalf("unexpected sources: %v", sources)
+	}
+}
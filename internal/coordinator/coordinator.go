@@ -2,10 +2,16 @@ package coordinator
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/nholik/swarm-sentinel/internal/compose"
 	"github.com/nholik/swarm-sentinel/internal/config"
+	"github.com/nholik/swarm-sentinel/internal/health"
 	"github.com/nholik/swarm-sentinel/internal/healthcheck"
 	"github.com/nholik/swarm-sentinel/internal/metrics"
 	"github.com/nholik/swarm-sentinel/internal/notify"
@@ -24,6 +30,7 @@ type Coordinator struct {
 	swarmClient              swarm.Client
 	stateStore               state.Store
 	stateMu                  *sync.Mutex
+	desiredCache             state.DesiredCache
 	notifier                 notify.Notifier
 	cycleTracker             *healthcheck.Tracker
 	metrics                  *metrics.Metrics
@@ -64,6 +71,14 @@ func WithStateStore(store state.Store, lock *sync.Mutex) Option {
 	}
 }
 
+// WithDesiredStateCache sets the last known good desired state cache shared by
+// all runners; entries are keyed by stack name.
+func WithDesiredStateCache(cache state.DesiredCache) Option {
+	return func(c *Coordinator) {
+		c.desiredCache = cache
+	}
+}
+
 // WithNotifier enables transition notifications for each runner.
 func WithNotifier(notifier notify.Notifier) Option {
 	return func(c *Coordinator) {
@@ -164,23 +179,38 @@ func (c *Coordinator) spawnRunner(ctx context.Context, wg *sync.WaitGroup, mappi
 		timeout = mapping.Timeout
 	}
 
-	// Create HTTP fetcher for this stack's compose URL
-	fetcher, err := compose.NewHTTPFetcher(mapping.ComposeURL, timeout, 0)
+	// Create a fetcher per compose URL (scheme selects the source type)
+	sources, err := ComposeSources(c.cfg, mapping, timeout)
+	if err != nil {
+		stackLogger.Error().Err(err).Msg("failed to initialize compose sources")
+		c.recordError(mapping.Name, err)
+		return
+	}
+
+	envFetcher, err := ComposeEnvFetcher(c.cfg, mapping, timeout)
 	if err != nil {
-		stackLogger.Error().Err(err).Msg("failed to initialize compose fetcher")
+		stackLogger.Error().Err(err).Msg("failed to initialize compose env file")
 		c.recordError(mapping.Name, err)
 		return
 	}
 
 	// Create runner for this stack
 	opts := []runner.Option{
-		runner.WithComposeFetcher(fetcher),
+		runner.WithComposeSources(sources...),
 		runner.WithSwarmClient(c.swarmClient),
 		runner.WithStackName(mapping.Name),
+		runner.WithSourceAlerts(c.cfg.SourceFailureThreshold, c.cfg.SourceMaxAge),
+		runner.WithHealthOptions(HealthOptions(c.cfg)...),
+	}
+	if envFetcher != nil {
+		opts = append(opts, runner.WithComposeEnv(envFetcher))
 	}
 	if c.stateStore != nil {
 		opts = append(opts, runner.WithStateStore(c.stateStore, c.stateMu))
 	}
+	if c.desiredCache != nil {
+		opts = append(opts, runner.WithDesiredStateCache(c.desiredCache))
+	}
 	if c.notifier != nil {
 		opts = append(opts, runner.WithNotifier(c.notifier))
 	}
@@ -218,6 +248,57 @@ func (c *Coordinator) spawnRunner(ctx context.Context, wg *sync.WaitGroup, mappi
 	}
 }
 
+// HealthOptions derives health evaluation options from the global
+// configuration.
+func HealthOptions(cfg config.Config) []health.EvaluateOption {
+	var opts []health.EvaluateOption
+	if len(cfg.LabelPrefixes) > 0 {
+		opts = append(opts, health.WithLabelPrefixes(cfg.LabelPrefixes...))
+	}
+	if cfg.PolicyDriftSeverity != "" {
+		opts = append(opts, health.WithPolicySeverity(health.ServiceStatus(strings.ToUpper(cfg.PolicyDriftSeverity))))
+	}
+	return opts
+}
+
+// SourceOptions derives compose fetcher options from the global configuration
+// and the stack's mapping entry (auth, custom headers and TLS overrides).
+func SourceOptions(cfg config.Config, mapping config.StackMapping) []compose.SourceOption {
+	opts := []compose.SourceOption{
+		compose.WithS3Config(compose.S3Config{
+			Endpoint:        cfg.S3Endpoint,
+			Region:          cfg.S3Region,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+			SessionToken:    cfg.S3SessionToken,
+			ForcePathStyle:  cfg.S3ForcePathStyle,
+		}),
+	}
+	if mapping.Auth != nil {
+		opts = append(opts, compose.WithHTTPAuth(compose.HTTPAuth{
+			Type:     mapping.Auth.Type,
+			Username: mapping.Auth.Username,
+			Password: mapping.Auth.Password,
+			Header:   mapping.Auth.Header,
+			Token:    mapping.Auth.Token,
+		}))
+	}
+	if len(mapping.Headers) > 0 {
+		opts = append(opts, compose.WithHTTPHeaders(mapping.Headers))
+	}
+	if usesNetworkSource(mapping.ComposeURL) {
+		tlsCfg := cfg.ComposeTLS.Merge(mapping.TLS)
+		opts = append(opts, compose.WithTLS(compose.TLSConfig{
+			CAFile:     tlsCfg.CAFile,
+			CertFile:   tlsCfg.CertFile,
+			KeyFile:    tlsCfg.KeyFile,
+			MinVersion: tlsCfg.MinVersion,
+			ServerName: tlsCfg.ServerName,
+		}))
+	}
+	return opts
+}
+
 // recordError records a per-stack error for later reporting.
 func (c *Coordinator) recordError(stackName string, err error) {
 	c.mu.Lock()
@@ -236,3 +317,130 @@ func (c *Coordinator) GetRunners() map[string]*runner.Runner {
 	}
 	return result
 }
+
+// ComposeSources builds a fetcher and optional signature verifier for each of
+// the stack's compose URLs, in merge order. Each source is named by its
+// redacted URL so parse errors point at the offending file.
+func ComposeSources(cfg config.Config, mapping config.StackMapping, timeout time.Duration) ([]runner.ComposeSource, error) {
+	urls := mapping.Sources()
+	sources := make([]runner.ComposeSource, 0, len(urls))
+	for _, composeURL := range urls {
+		sourceMapping := mapping
+		sourceMapping.ComposeURL = composeURL
+		sourceMapping.ComposeURLs = nil
+
+		fetcher, err := compose.NewFetcher(composeURL, timeout, 0, SourceOptions(cfg, sourceMapping)...)
+		if err != nil {
+			return nil, fmt.Errorf("compose fetcher: %w", err)
+		}
+		verifier, err := ComposeVerifier(cfg, sourceMapping, timeout)
+		if err != nil {
+			return nil, fmt.Errorf("compose signature verification: %w", err)
+		}
+		sources = append(sources, runner.ComposeSource{
+			Name:     compose.RedactURL(composeURL),
+			Fetcher:  fetcher,
+			Verifier: verifier,
+		})
+	}
+	return sources, nil
+}
+
+// ComposeVerifier builds the signature verifier for a stack from its mapping
+// entry and the global settings. It returns nil when verification is not
+// configured. Source credentials are only reused when the signature lives on
+// the same host as the compose file.
+func ComposeVerifier(cfg config.Config, mapping config.StackMapping, timeout time.Duration) (compose.Verifier, error) {
+	var keyPaths []string
+	signatureURL := ""
+	if cfg.ComposeSignature != nil {
+		keyPaths = cfg.ComposeSignature.PublicKeys
+	}
+	if mapping.Signature != nil {
+		if len(mapping.Signature.PublicKeys) > 0 {
+			keyPaths = mapping.Signature.PublicKeys
+		}
+		signatureURL = mapping.Signature.URL
+	}
+	if len(keyPaths) == 0 {
+		if mapping.Signature != nil {
+			return nil, errors.New("signature verification requires public_keys or SS_COMPOSE_SIGNATURE_KEYS")
+		}
+		return nil, nil
+	}
+
+	keys, err := compose.LoadPublicKeys(keyPaths)
+	if err != nil {
+		return nil, err
+	}
+	if signatureURL == "" {
+		signatureURL, err = compose.SignatureURL(mapping.ComposeURL)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sourceOpts := SourceOptions(cfg, config.StackMapping{ComposeURL: signatureURL})
+	if sameOrigin(mapping.ComposeURL, signatureURL) {
+		signatureMapping := mapping
+		signatureMapping.ComposeURL = signatureURL
+		sourceOpts = SourceOptions(cfg, signatureMapping)
+	}
+	verifier, err := compose.NewSignatureVerifier(signatureURL, keys, timeout, sourceOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return verifier, nil
+}
+
+// ComposeEnvFetcher builds the fetcher for a stack's interpolation env file.
+// It returns nil when interpolation is not enabled. As with signatures, source
+// credentials are only reused when the env file lives on the same host as the
+// first compose file.
+func ComposeEnvFetcher(cfg config.Config, mapping config.StackMapping, timeout time.Duration) (compose.Fetcher, error) {
+	if mapping.EnvFile == "" {
+		return nil, nil
+	}
+	envURL := config.EnvFileURL(mapping.EnvFile)
+
+	sourceOpts := SourceOptions(cfg, config.StackMapping{ComposeURL: envURL})
+	if sources := mapping.Sources(); len(sources) > 0 && sameOrigin(sources[0], envURL) {
+		envMapping := mapping
+		envMapping.ComposeURL = envURL
+		envMapping.ComposeURLs = nil
+		sourceOpts = SourceOptions(cfg, envMapping)
+	}
+	fetcher, err := compose.NewFetcher(envURL, timeout, 0, sourceOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("env file: %w", err)
+	}
+	return fetcher, nil
+}
+
+func sameOrigin(a, b string) bool {
+	left, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+	right, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+	return left.Scheme == right.Scheme && left.Host == right.Host
+}
+
+// usesNetworkSource reports whether the compose URL is fetched over the
+// network, where TLS settings apply. Global TLS settings are skipped for
+// local file and git sources.
+func usesNetworkSource(composeURL string) bool {
+	parsed, err := url.Parse(composeURL)
+	if err != nil {
+		return false
+	}
+	switch parsed.Scheme {
+	case "http", "https", "s3":
+		return true
+	default:
+		return false
+	}
+}
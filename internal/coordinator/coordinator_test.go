@@ -2,8 +2,14 @@ package coordinator
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -343,3 +349,41 @@ func waitForRunners(t *testing.T, coord *Coordinator, expected int, timeout time
 		}
 	}
 }
+
+func TestComposeVerifier(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "cosign.pub")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+
+	mapping := config.StackMapping{Name: "prod", ComposeURL: "https://example.com/compose.yml"}
+
+	verifier, err := ComposeVerifier(config.Config{}, mapping, time.Second)
+	if err != nil || verifier != nil {
+		t.Fatalf("expected no verifier when unconfigured, got %v, %v", verifier, err)
+	}
+
+	global := config.Config{ComposeSignature: &config.SignatureConfig{PublicKeys: []string{keyPath}}}
+	verifier, err = ComposeVerifier(global, mapping, time.Second)
+	if err != nil || verifier == nil {
+		t.Fatalf("expected verifier from global keys, got %v, %v", verifier, err)
+	}
+
+	mapping.Signature = &config.SignatureConfig{URL: "file:///srv/compose.yml.sig"}
+	if _, err := ComposeVerifier(config.Config{}, mapping, time.Second); err == nil {
+		t.Fatal("expected error for signature block without keys")
+	}
+
+	mapping.Signature.PublicKeys = []string{filepath.Join(t.TempDir(), "missing.pub")}
+	if _, err := ComposeVerifier(global, mapping, time.Second); err == nil {
+		t.Fatal("expected error for unreadable key")
+	}
+}
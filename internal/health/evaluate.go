@@ -2,14 +2,24 @@ package health
 
 import (
 	"fmt"
+	"maps"
+	"math"
+	"path"
 	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/nholik/swarm-sentinel/internal/compose"
 	"github.com/nholik/swarm-sentinel/internal/swarm"
 )
 
 // EvaluateStackHealth compares desired and actual state to compute health.
-func EvaluateStackHealth(desired compose.DesiredState, actual *swarm.ActualState, stackScoped bool) StackHealth {
+func EvaluateStackHealth(desired compose.DesiredState, actual *swarm.ActualState, stackScoped bool, opts ...EvaluateOption) StackHealth {
+	options := evaluateOptions{policySeverity: StatusDegraded}
+	for _, opt := range opts {
+		opt(&options)
+	}
 	if actual == nil {
 		actual = &swarm.ActualState{Services: map[string]swarm.ActualService{}}
 	}
@@ -36,7 +46,7 @@ func EvaluateStackHealth(desired compose.DesiredState, actual *swarm.ActualState
 			result.Status = worsenStatus(result.Status, health.Status)
 			continue
 		}
-		health := evaluateService(name, desiredService, actualService)
+		health := evaluateService(name, desiredService, actualService, options)
 		result.Services[name] = health
 		result.Status = worsenStatus(result.Status, health.Status)
 	}
@@ -69,7 +79,7 @@ func EvaluateStackHealth(desired compose.DesiredState, actual *swarm.ActualState
 	return result
 }
 
-func evaluateService(name string, desired compose.DesiredService, actual swarm.ActualService) ServiceHealth {
+func evaluateService(name string, desired compose.DesiredService, actual swarm.ActualService, options evaluateOptions) ServiceHealth {
 	health := ServiceHealth{
 		Name:   name,
 		Status: StatusOK,
@@ -84,15 +94,37 @@ func evaluateService(name string, desired compose.DesiredService, actual swarm.A
 		health.Reasons = append(health.Reasons, fmt.Sprintf("image mismatch: want %s got %s", desiredImage, actualImage))
 	}
 
+	modeMismatch := desired.Mode != "" && actual.Mode != "" && desired.Mode != actual.Mode
+	if modeMismatch {
+		health.Status = worsenStatus(health.Status, StatusDegraded)
+		health.Reasons = append(health.Reasons, fmt.Sprintf("deploy mode mismatch: compose declares %s but Swarm runs %s", desired.Mode, actual.Mode))
+		health.Drift = append(health.Drift, DriftDetail{
+			Kind:     DriftChanged,
+			Resource: "service",
+			Name:     "mode",
+			Desired:  desired.Mode,
+			Actual:   actual.Mode,
+		})
+	}
+
+	// Global modes have no replica count in compose, and a desired count
+	// means nothing for a service running in another mode, so both follow
+	// the target Swarm reports.
 	desiredReplicas := desired.Replicas
-	if desired.Mode == "global" {
+	if desired.Mode == "global" || desired.Mode == "global-job" || modeMismatch {
 		desiredReplicas = actual.DesiredReplicas
 	}
 	health.DesiredReplicas = desiredReplicas
 	health.RunningReplicas = actual.RunningReplicas
 
 	updateInProgress := actual.UpdateState == "updating" || actual.UpdateState == "rollback_started"
-	if desiredReplicas > 0 {
+	jobMode := actual.Mode
+	if jobMode == "" {
+		jobMode = desired.Mode
+	}
+	if jobMode == "replicated-job" || jobMode == "global-job" {
+		evaluateJob(&health, jobMode, desired, actual)
+	} else if desiredReplicas > 0 {
 		switch {
 		case actual.RunningReplicas == 0:
 			health.Status = worsenStatus(health.Status, StatusFailed)
@@ -108,14 +140,50 @@ func evaluateService(name string, desired compose.DesiredService, actual swarm.A
 		}
 	}
 
+	applyDigestDrift(&health, desired.Image, actual, updateInProgress)
+
 	health.Reasons, health.Drift = applyDrift(health.Reasons, health.Drift, "config", desired.Configs, actual.Configs)
 	health.Reasons, health.Drift = applyDrift(health.Reasons, health.Drift, "secret", desired.Secrets, actual.Secrets)
+	applyPortDrift(&health, desired.Ports, actual.Ports)
+	applyEnvDrift(&health, desired.Environment, actual.Environment, desired.IgnoreEnv)
+	applyLabelDrift(&health, "label", desired.Labels, actual.Labels, options.labelPrefixes)
+	applyLabelDrift(&health, "container_label", desired.ContainerLabels, actual.ContainerLabels, options.labelPrefixes)
+	applyResourceDrift(&health, "limits", desired.Resources.Limits, actual.Resources.Limits)
+	applyResourceDrift(&health, "reservations", desired.Resources.Reservations, actual.Resources.Reservations)
+	applyPlacementDrift(&health, desired.Placement, actual.Placement)
+	applyNetworkDrift(&health, desired.Networks, actual.Networks)
+	applyUpdatePolicyDrift(&health, "update_config", desired.UpdateConfig, actual.UpdateConfig, options.policySeverity)
+	applyUpdatePolicyDrift(&health, "rollback_config", desired.RollbackConfig, actual.RollbackConfig, options.policySeverity)
+	applyRestartPolicyDrift(&health, desired.RestartPolicy, actual.RestartPolicy, options.policySeverity)
+	applyHealthcheckDrift(&health, desired.Healthcheck, actual.Healthcheck)
+	applyMountDrift(&health, desired.Mounts, actual.Mounts)
+	applyRuntimeDrift(&health, desired, actual)
+	if desired.Mode == "replicated-job" && actual.Mode == "replicated-job" {
+		applyChangedSettings(&health, "job", []policySetting{
+			{name: "max_concurrent", want: formatCount(int64(desired.MaxConcurrent)), got: formatCount(int64(actual.MaxConcurrent))},
+			{name: "total_completions", want: formatCount(int64(desired.TotalCompletions)), got: formatCount(int64(actual.TotalCompletions))},
+		})
+	}
+
+	if desired.EndpointMode != "" && actual.EndpointMode != "" && desired.EndpointMode != actual.EndpointMode {
+		health.Status = worsenStatus(health.Status, StatusDegraded)
+		health.Reasons = append(health.Reasons, fmt.Sprintf("endpoint mode mismatch: want %s got %s", desired.EndpointMode, actual.EndpointMode))
+		health.Drift = append(health.Drift, DriftDetail{
+			Kind:     DriftChanged,
+			Resource: "endpoint",
+			Name:     "mode",
+			Desired:  desired.EndpointMode,
+			Actual:   actual.EndpointMode,
+		})
+	}
 
 	for _, drift := range health.Drift {
-		switch drift.Kind {
-		case DriftMissing:
+		switch {
+		case drift.Resource == "env":
+			// applyEnvDrift rates env drift itself.
+		case drift.Kind == DriftMissing:
 			health.Status = worsenStatus(health.Status, StatusFailed)
-		case DriftExtra:
+		case drift.Kind == DriftExtra:
 			health.Status = worsenStatus(health.Status, StatusDegraded)
 		}
 	}
@@ -144,6 +212,687 @@ func applyDrift(reasons []string, drift []DriftDetail, resource string, desired,
 	return reasons, drift
 }
 
+// applyPortDrift matches published ports by target port and protocol. A port
+// published on a different number or mode than compose declares is reported
+// as changed and fails the service, since clients cannot reach it. A desired
+// published port of 0 matches whatever port Swarm assigned.
+func applyPortDrift(health *ServiceHealth, desired []compose.PortMapping, actual []swarm.PortMapping) {
+	used := make([]bool, len(actual))
+	unmatched := make([]compose.PortMapping, 0)
+	for _, want := range desired {
+		matched := false
+		for i, got := range actual {
+			if used[i] || got.Target != want.Target || got.Protocol != want.Protocol || got.Mode != want.Mode {
+				continue
+			}
+			if want.Published != 0 && want.Published != got.Published {
+				continue
+			}
+			used[i], matched = true, true
+			break
+		}
+		if !matched {
+			unmatched = append(unmatched, want)
+		}
+	}
+
+	for _, want := range unmatched {
+		wantName := formatPort(want.Target, want.Published, want.Protocol, want.Mode)
+		changed := false
+		for i, got := range actual {
+			if used[i] || got.Target != want.Target || got.Protocol != want.Protocol {
+				continue
+			}
+			used[i], changed = true, true
+			gotName := formatPort(got.Target, got.Published, got.Protocol, got.Mode)
+			health.Status = worsenStatus(health.Status, StatusFailed)
+			health.Reasons = append(health.Reasons, fmt.Sprintf("port %d/%s changed: want %s got %s", want.Target, want.Protocol, wantName, gotName))
+			health.Drift = append(health.Drift, DriftDetail{
+				Kind:     DriftChanged,
+				Resource: "port",
+				Name:     fmt.Sprintf("%d/%s", want.Target, want.Protocol),
+				Desired:  wantName,
+				Actual:   gotName,
+			})
+			break
+		}
+		if changed {
+			continue
+		}
+		health.Reasons = append(health.Reasons, fmt.Sprintf("missing port: %s", wantName))
+		health.Drift = append(health.Drift, DriftDetail{
+			Kind:     DriftMissing,
+			Resource: "port",
+			Name:     wantName,
+		})
+	}
+
+	for i, got := range actual {
+		if used[i] {
+			continue
+		}
+		gotName := formatPort(got.Target, got.Published, got.Protocol, got.Mode)
+		health.Reasons = append(health.Reasons, fmt.Sprintf("extra port: %s", gotName))
+		health.Drift = append(health.Drift, DriftDetail{
+			Kind:     DriftExtra,
+			Resource: "port",
+			Name:     gotName,
+		})
+	}
+}
+
+// applyEnvDrift compares environment variables by value digest. Only names
+// appear in reasons and drift details, never values. Any env drift degrades
+// the service: a variable removed with --env-rm is no more severe than one
+// whose value changed.
+func applyEnvDrift(health *ServiceHealth, desired, actual map[string]string, ignore []string) {
+	desiredNames := envNames(desired, ignore)
+	actualNames := envNames(actual, ignore)
+	before := len(health.Drift)
+	health.Reasons, health.Drift = applyDrift(health.Reasons, health.Drift, "env", desiredNames, actualNames)
+	if len(health.Drift) > before {
+		health.Status = worsenStatus(health.Status, StatusDegraded)
+	}
+
+	for _, name := range desiredNames {
+		got, ok := actual[name]
+		if !ok || got == desired[name] {
+			continue
+		}
+		health.Status = worsenStatus(health.Status, StatusDegraded)
+		health.Reasons = append(health.Reasons, fmt.Sprintf("changed env: %s", name))
+		health.Drift = append(health.Drift, DriftDetail{
+			Kind:     DriftChanged,
+			Resource: "env",
+			Name:     name,
+		})
+	}
+}
+
+// applyLabelDrift compares labels whose keys match prefixes, or all labels
+// when prefixes is empty. Label values are not secret and are reported.
+func applyLabelDrift(health *ServiceHealth, resource string, desired, actual map[string]string, prefixes []string) {
+	keys := make(map[string]struct{}, len(desired)+len(actual))
+	for key := range desired {
+		keys[key] = struct{}{}
+	}
+	for key := range actual {
+		keys[key] = struct{}{}
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		if labelCompared(key, prefixes) {
+			sorted = append(sorted, key)
+		}
+	}
+	sort.Strings(sorted)
+
+	label := strings.ReplaceAll(resource, "_", " ")
+	for _, key := range sorted {
+		want, hasWant := desired[key]
+		got, hasGot := actual[key]
+		var reason string
+		switch {
+		case hasWant && !hasGot:
+			reason = fmt.Sprintf("missing %s: %s", label, key)
+		case !hasWant && hasGot:
+			reason = fmt.Sprintf("extra %s: %s", label, key)
+		case want != got:
+			reason = fmt.Sprintf("%s %s changed: want %q got %q", label, key, want, got)
+		default:
+			continue
+		}
+		health.Status = worsenStatus(health.Status, StatusDegraded)
+		health.Reasons = append(health.Reasons, reason)
+		health.Drift = append(health.Drift, DriftDetail{
+			Kind:     DriftLabel,
+			Resource: resource,
+			Name:     key,
+			Desired:  want,
+			Actual:   got,
+		})
+	}
+}
+
+// applyResourceDrift reports each resource setting that differs, with the
+// compose and Swarm values in the drift detail. CPUs are compared in
+// millicores, since compose stores them as a float. Unset values are empty.
+func applyResourceDrift(health *ServiceHealth, scope string, desired compose.ResourceSpec, actual swarm.ResourceSpec) {
+	type setting struct {
+		name      string
+		want, got string
+	}
+	settings := []setting{
+		{name: "cpus", want: formatCPUs(desired.NanoCPUs), got: formatCPUs(actual.NanoCPUs)},
+		{name: "memory", want: formatCount(desired.MemoryBytes), got: formatCount(actual.MemoryBytes)},
+		{name: "pids", want: formatCount(desired.Pids), got: formatCount(actual.Pids)},
+	}
+	kinds := make([]string, 0, len(desired.Generic)+len(actual.Generic))
+	for kind := range desired.Generic {
+		kinds = append(kinds, kind)
+	}
+	for kind := range actual.Generic {
+		if _, ok := desired.Generic[kind]; !ok {
+			kinds = append(kinds, kind)
+		}
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		settings = append(settings, setting{
+			name: "generic_resources." + kind,
+			want: formatCount(desired.Generic[kind]),
+			got:  formatCount(actual.Generic[kind]),
+		})
+	}
+
+	for _, item := range settings {
+		if item.want == item.got {
+			continue
+		}
+		name := scope + "." + item.name
+		health.Status = worsenStatus(health.Status, StatusDegraded)
+		health.Reasons = append(health.Reasons, fmt.Sprintf("resource %s changed", name))
+		health.Drift = append(health.Drift, DriftDetail{
+			Kind:     DriftChanged,
+			Resource: "resources",
+			Name:     name,
+			Desired:  item.want,
+			Actual:   item.got,
+		})
+	}
+}
+
+// applyPlacementDrift compares scheduling rules. Constraints are compared
+// after normalizing whitespace around their operator, since Swarm stores them
+// as written.
+func applyPlacementDrift(health *ServiceHealth, desired compose.Placement, actual swarm.Placement) {
+	settings := []struct {
+		name      string
+		want, got string
+	}{
+		{name: "constraints", want: joinConstraints(desired.Constraints), got: joinConstraints(actual.Constraints)},
+		{name: "preferences", want: strings.Join(desired.Preferences, ", "), got: strings.Join(actual.Preferences, ", ")},
+		{name: "max_replicas_per_node", want: formatCount(int64(desired.MaxReplicasPerNode)), got: formatCount(int64(actual.MaxReplicasPerNode))},
+	}
+	for _, item := range settings {
+		if item.want == item.got {
+			continue
+		}
+		health.Status = worsenStatus(health.Status, StatusDegraded)
+		health.Reasons = append(health.Reasons, fmt.Sprintf("placement %s changed", strings.ReplaceAll(item.name, "_", " ")))
+		health.Drift = append(health.Drift, DriftDetail{
+			Kind:     DriftPlacement,
+			Resource: "placement",
+			Name:     item.name,
+			Desired:  item.want,
+			Actual:   item.got,
+		})
+	}
+}
+
+// applyNetworkDrift compares the networks a service joins. Missing and extra
+// networks are reported like configs and secrets; a network joined with
+// different aliases is reported as changed. Desired networks are only resolved
+// for a known stack, so nothing is compared when they are nil.
+func applyNetworkDrift(health *ServiceHealth, desired []compose.NetworkAttachment, actual []swarm.NetworkAttachment) {
+	if desired == nil {
+		return
+	}
+	desiredNames := make([]string, 0, len(desired))
+	for _, network := range desired {
+		desiredNames = append(desiredNames, network.Name)
+	}
+	actualNames := make([]string, 0, len(actual))
+	actualAliases := make(map[string]string, len(actual))
+	for _, network := range actual {
+		actualNames = append(actualNames, network.Name)
+		actualAliases[network.Name] = strings.Join(network.Aliases, ", ")
+	}
+	health.Reasons, health.Drift = applyDrift(health.Reasons, health.Drift, "network", desiredNames, actualNames)
+
+	for _, network := range desired {
+		got, ok := actualAliases[network.Name]
+		want := strings.Join(network.Aliases, ", ")
+		if !ok || got == want {
+			continue
+		}
+		health.Status = worsenStatus(health.Status, StatusDegraded)
+		health.Reasons = append(health.Reasons, fmt.Sprintf("network %s aliases changed", network.Name))
+		health.Drift = append(health.Drift, DriftDetail{
+			Kind:     DriftChanged,
+			Resource: "network",
+			Name:     network.Name,
+			Desired:  want,
+			Actual:   got,
+		})
+	}
+}
+
+// policySetting is one compared field of a deploy policy or healthcheck,
+// rendered as it is reported.
+type policySetting struct {
+	name      string
+	want, got string
+}
+
+// applyUpdatePolicyDrift compares an update or rollback config. Policies are
+// only compared when both sides were resolved; a zero policy means the state
+// did not carry one.
+func applyUpdatePolicyDrift(health *ServiceHealth, resource string, desired compose.UpdatePolicy, actual swarm.UpdatePolicy, status ServiceStatus) {
+	if desired == (compose.UpdatePolicy{}) || actual == (swarm.UpdatePolicy{}) {
+		return
+	}
+	applyPolicyDrift(health, resource, status, []policySetting{
+		{name: "parallelism", want: strconv.FormatUint(desired.Parallelism, 10), got: strconv.FormatUint(actual.Parallelism, 10)},
+		{name: "delay", want: desired.Delay.String(), got: actual.Delay.String()},
+		{name: "failure_action", want: desired.FailureAction, got: actual.FailureAction},
+		{name: "monitor", want: desired.Monitor.String(), got: actual.Monitor.String()},
+		{name: "max_failure_ratio", want: formatRatio(desired.MaxFailureRatio), got: formatRatio(actual.MaxFailureRatio)},
+		{name: "order", want: desired.Order, got: actual.Order},
+	})
+}
+
+func applyRestartPolicyDrift(health *ServiceHealth, desired compose.RestartPolicy, actual swarm.RestartPolicy, status ServiceStatus) {
+	if desired == (compose.RestartPolicy{}) || actual == (swarm.RestartPolicy{}) {
+		return
+	}
+	applyPolicyDrift(health, "restart_policy", status, []policySetting{
+		{name: "condition", want: desired.Condition, got: actual.Condition},
+		{name: "delay", want: desired.Delay.String(), got: actual.Delay.String()},
+		{name: "max_attempts", want: strconv.FormatUint(desired.MaxAttempts, 10), got: strconv.FormatUint(actual.MaxAttempts, 10)},
+		{name: "window", want: desired.Window.String(), got: actual.Window.String()},
+	})
+}
+
+func applyPolicyDrift(health *ServiceHealth, resource string, status ServiceStatus, settings []policySetting) {
+	for _, item := range settings {
+		if item.want == item.got {
+			continue
+		}
+		health.Status = worsenStatus(health.Status, status)
+		health.Reasons = append(health.Reasons, fmt.Sprintf("%s %s changed: want %s got %s", resource, item.name, item.want, item.got))
+		health.Drift = append(health.Drift, DriftDetail{
+			Kind:     DriftPolicy,
+			Resource: resource,
+			Name:     item.name,
+			Desired:  item.want,
+			Actual:   item.got,
+		})
+	}
+}
+
+// applyHealthcheckDrift compares healthcheck definitions. A missing
+// healthcheck, an empty test and zero settings all inherit the image's, so
+// they compare equal to each other. Timing settings are not compared when
+// either side is disabled.
+func applyHealthcheckDrift(health *ServiceHealth, desired *compose.Healthcheck, actual *swarm.Healthcheck) {
+	var want, got compose.Healthcheck
+	if desired != nil {
+		want = *desired
+	}
+	if actual != nil {
+		got = compose.Healthcheck(*actual)
+	}
+
+	wantTest, gotTest := formatHealthTest(want.Test), formatHealthTest(got.Test)
+	settings := []policySetting{{name: "test", want: wantTest, got: gotTest}}
+	if wantTest != healthTestNone && gotTest != healthTestNone {
+		settings = append(settings,
+			policySetting{name: "interval", want: formatDuration(want.Interval), got: formatDuration(got.Interval)},
+			policySetting{name: "timeout", want: formatDuration(want.Timeout), got: formatDuration(got.Timeout)},
+			policySetting{name: "start_period", want: formatDuration(want.StartPeriod), got: formatDuration(got.StartPeriod)},
+			policySetting{name: "retries", want: formatCount(int64(want.Retries)), got: formatCount(int64(got.Retries))},
+		)
+	}
+	applyChangedSettings(health, "healthcheck", settings)
+}
+
+// applyRuntimeDrift compares the process settings of the container: command,
+// entrypoint, user, working directory, hostname and stop behaviour. An unset
+// command or entrypoint means the image's is used.
+func applyRuntimeDrift(health *ServiceHealth, desired compose.DesiredService, actual swarm.ActualService) {
+	settings := []policySetting{
+		{name: "command", want: formatArgs(desired.Command), got: formatArgs(actual.Command)},
+		{name: "entrypoint", want: formatArgs(desired.Entrypoint), got: formatArgs(actual.Entrypoint)},
+		{name: "user", want: desired.User, got: actual.User},
+		{name: "working_dir", want: desired.WorkingDir, got: actual.WorkingDir},
+		{name: "hostname", want: desired.Hostname, got: actual.Hostname},
+		{name: "stop_signal", want: desired.StopSignal, got: actual.StopSignal},
+		{name: "stop_grace_period", want: formatDuration(desired.StopGracePeriod), got: formatDuration(actual.StopGracePeriod)},
+	}
+	applyChangedSettings(health, "container", settings)
+}
+
+// applyDigestDrift compares image content, which the tag comparison above
+// cannot see. A digest pinned in compose must be the one in the service spec,
+// and running tasks must all use the spec's digest. Tasks are not compared
+// during an update, which mixes digests until it finishes.
+func applyDigestDrift(health *ServiceHealth, desiredImage string, actual swarm.ActualService, updateInProgress bool) {
+	want := swarm.ImageDigest(desiredImage)
+	got := swarm.ImageDigest(actual.Image)
+	if want != "" && want != got {
+		health.Status = worsenStatus(health.Status, StatusDegraded)
+		health.Reasons = append(health.Reasons, fmt.Sprintf("image digest mismatch: want %s got %s", shortDigest(want), shortDigest(got)))
+		health.Drift = append(health.Drift, DriftDetail{
+			Kind:     DriftImageDigest,
+			Resource: "image",
+			Name:     "service",
+			Desired:  want,
+			Actual:   got,
+		})
+	}
+
+	if updateInProgress || len(actual.TaskDigests) == 0 {
+		return
+	}
+	switch {
+	case len(actual.TaskDigests) > 1:
+		short := make([]string, 0, len(actual.TaskDigests))
+		for _, digest := range actual.TaskDigests {
+			short = append(short, shortDigest(digest))
+		}
+		health.Reasons = append(health.Reasons, "running tasks use mixed image digests: "+strings.Join(short, ", "))
+	case got != "" && actual.TaskDigests[0] != got:
+		health.Reasons = append(health.Reasons, fmt.Sprintf("running tasks use image digest %s, service spec has %s", shortDigest(actual.TaskDigests[0]), shortDigest(got)))
+	default:
+		return
+	}
+	health.Status = worsenStatus(health.Status, StatusDegraded)
+	health.Drift = append(health.Drift, DriftDetail{
+		Kind:     DriftImageDigest,
+		Resource: "image",
+		Name:     "tasks",
+		Desired:  got,
+		Actual:   strings.Join(actual.TaskDigests, ","),
+	})
+}
+
+// shortDigest abbreviates a digest for reasons; drift details keep the full
+// value.
+func shortDigest(digest string) string {
+	if digest == "" {
+		return "none"
+	}
+	const length = len("sha256:") + 12
+	if len(digest) > length {
+		return digest[:length]
+	}
+	return digest
+}
+
+// evaluateJob judges a job by the tasks of its current run. A replicated job
+// is done once total_completions tasks completed; a global job once every
+// node Swarm scheduled it on completed. Failed tasks are retried by Swarm as
+// the restart policy allows, so they only fail the job once nothing is left
+// running.
+func evaluateJob(health *ServiceHealth, mode string, desired compose.DesiredService, actual swarm.ActualService) {
+	total := actual.TotalCompletions
+	if mode == "global-job" {
+		total = max(actual.DesiredReplicas, actual.CompletedTasks)
+	} else if desired.Mode == mode && desired.TotalCompletions > 0 {
+		total = desired.TotalCompletions
+	}
+	job := &JobHealth{
+		Completed: actual.CompletedTasks,
+		Total:     total,
+		Running:   actual.RunningReplicas,
+		Failed:    actual.FailedTasks,
+		Attempts:  actual.CompletedTasks + actual.RunningReplicas + actual.FailedTasks,
+	}
+	health.Job = job
+
+	switch {
+	case job.Total > 0 && job.Completed >= job.Total:
+		job.State = JobSucceeded
+	case job.Running == 0 && job.Failed > 0:
+		job.State = JobFailed
+		health.Status = worsenStatus(health.Status, StatusFailed)
+		health.Reasons = append(health.Reasons, fmt.Sprintf("job failed: %d/%d completed, %d failed attempts", job.Completed, job.Total, job.Failed))
+	default:
+		job.State = JobRunning
+		if job.Failed > 0 {
+			health.Status = worsenStatus(health.Status, StatusDegraded)
+			health.Reasons = append(health.Reasons, fmt.Sprintf("job running: %d/%d completed, %d failed attempts", job.Completed, job.Total, job.Failed))
+		}
+	}
+}
+
+// applyChangedSettings reports each setting that differs as DriftChanged and
+// degrades the service.
+func applyChangedSettings(health *ServiceHealth, resource string, settings []policySetting) {
+	for _, item := range settings {
+		if item.want == item.got {
+			continue
+		}
+		health.Status = worsenStatus(health.Status, StatusDegraded)
+		health.Reasons = append(health.Reasons, fmt.Sprintf("%s %s changed", resource, strings.ReplaceAll(item.name, "_", " ")))
+		health.Drift = append(health.Drift, DriftDetail{
+			Kind:     DriftChanged,
+			Resource: resource,
+			Name:     item.name,
+			Desired:  item.want,
+			Actual:   item.got,
+		})
+	}
+}
+
+// applyMountDrift compares mounts keyed by target. Missing and extra mounts
+// are reported like configs and secrets; a mount whose type, source,
+// read-only flag or volume driver differs is reported as changed. Desired
+// mounts are only resolved for a known stack, so nothing is compared when
+// they are nil.
+func applyMountDrift(health *ServiceHealth, desired []compose.Mount, actual []swarm.Mount) {
+	if desired == nil {
+		return
+	}
+	desiredTargets := make([]string, 0, len(desired))
+	for _, mount := range desired {
+		desiredTargets = append(desiredTargets, mount.Target)
+	}
+	actualTargets := make([]string, 0, len(actual))
+	actualByTarget := make(map[string]compose.Mount, len(actual))
+	for _, mount := range actual {
+		actualTargets = append(actualTargets, mount.Target)
+		actualByTarget[mount.Target] = compose.Mount(mount)
+	}
+	health.Reasons, health.Drift = applyDrift(health.Reasons, health.Drift, "mount", desiredTargets, actualTargets)
+
+	for _, want := range desired {
+		got, ok := actualByTarget[want.Target]
+		if !ok || mountsMatch(want, got) {
+			continue
+		}
+		health.Status = worsenStatus(health.Status, StatusDegraded)
+		health.Reasons = append(health.Reasons, fmt.Sprintf("mount %s changed", want.Target))
+		health.Drift = append(health.Drift, DriftDetail{
+			Kind:     DriftChanged,
+			Resource: "mount",
+			Name:     want.Target,
+			Desired:  formatMount(want),
+			Actual:   formatMount(got),
+		})
+	}
+}
+
+// mountsMatch compares two mounts with the same target. A relative bind
+// source matches any absolute path ending in it, since stacks are deployed
+// from a directory the sentinel cannot see.
+func mountsMatch(want, got compose.Mount) bool {
+	if want.Type != got.Type || want.ReadOnly != got.ReadOnly || want.Driver != got.Driver {
+		return false
+	}
+	if !maps.Equal(want.DriverOptions, got.DriverOptions) {
+		return false
+	}
+	if want.Type == "bind" && want.Source != "" && !path.IsAbs(want.Source) {
+		return got.Source == want.Source || strings.HasSuffix(got.Source, "/"+path.Clean(want.Source))
+	}
+	return want.Source == got.Source
+}
+
+func formatMount(mount compose.Mount) string {
+	var b strings.Builder
+	b.WriteString(mount.Type)
+	b.WriteString(" ")
+	if mount.Source != "" {
+		b.WriteString(mount.Source)
+		b.WriteString(":")
+	}
+	b.WriteString(mount.Target)
+	if mount.ReadOnly {
+		b.WriteString(":ro")
+	}
+	if mount.Driver != "" {
+		b.WriteString(" driver=")
+		b.WriteString(mount.Driver)
+	}
+	if len(mount.DriverOptions) > 0 {
+		options := make([]string, 0, len(mount.DriverOptions))
+		for key, value := range mount.DriverOptions {
+			options = append(options, key+"="+value)
+		}
+		sort.Strings(options)
+		b.WriteString(" options=")
+		b.WriteString(strings.Join(options, ","))
+	}
+	return b.String()
+}
+
+const healthTestNone = "NONE"
+
+// formatHealthTest renders a healthcheck test in a canonical form. A shell
+// command run through CMD as `sh -c` is the CMD-SHELL form, and CMD-SHELL
+// arguments are joined the way the daemon joins them.
+func formatHealthTest(test []string) string {
+	if len(test) == 0 {
+		return ""
+	}
+	switch test[0] {
+	case healthTestNone:
+		return healthTestNone
+	case "CMD-SHELL":
+		return "CMD-SHELL " + strings.TrimSpace(strings.Join(test[1:], " "))
+	case "CMD":
+		args := test[1:]
+		if len(args) == 3 && (args[0] == "sh" || args[0] == "/bin/sh") && args[1] == "-c" {
+			return "CMD-SHELL " + strings.TrimSpace(args[2])
+		}
+		return strings.TrimSpace("CMD " + formatArgs(args))
+	default:
+		return strings.Join(test, " ")
+	}
+}
+
+// formatArgs joins command arguments, quoting those that are empty or contain
+// whitespace or quotes so different argument lists never render the same.
+func formatArgs(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\"") {
+			arg = strconv.Quote(arg)
+		}
+		quoted = append(quoted, arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
+}
+
+func formatRatio(ratio float32) string {
+	return strconv.FormatFloat(float64(ratio), 'f', -1, 32)
+}
+
+func joinConstraints(constraints []string) string {
+	normalized := make([]string, 0, len(constraints))
+	for _, constraint := range constraints {
+		normalized = append(normalized, normalizeConstraint(constraint))
+	}
+	sort.Strings(normalized)
+	return strings.Join(normalized, ", ")
+}
+
+func normalizeConstraint(constraint string) string {
+	for _, operator := range []string{"==", "!="} {
+		if key, value, ok := strings.Cut(constraint, operator); ok {
+			return strings.TrimSpace(key) + operator + strings.TrimSpace(value)
+		}
+	}
+	return strings.TrimSpace(constraint)
+}
+
+func formatCPUs(nanoCPUs int64) string {
+	if nanoCPUs == 0 {
+		return ""
+	}
+	millis := math.Round(float64(nanoCPUs) / 1e6)
+	return strconv.FormatFloat(millis/1000, 'f', -1, 64)
+}
+
+func formatCount(value int64) string {
+	if value == 0 {
+		return ""
+	}
+	return strconv.FormatInt(value, 10)
+}
+
+func labelCompared(key string, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// envNames returns the sorted variable names not excluded by ignore. Entries
+// ending in "*" match by prefix.
+func envNames(env map[string]string, ignore []string) []string {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		if !envIgnored(name, ignore) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func envIgnored(name string, ignore []string) bool {
+	for _, pattern := range ignore {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+			continue
+		}
+		if name == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// formatPort renders a port the way `docker service ls` does, noting host
+// mode since it changes how the port is reached.
+func formatPort(target, published uint32, protocol, mode string) string {
+	name := fmt.Sprintf("%d/%s", target, protocol)
+	if published != 0 {
+		name = fmt.Sprintf("%d:%s", published, name)
+	}
+	if mode == "host" {
+		name += " (host)"
+	}
+	return name
+}
+
 func diffNames(desired, actual []string) ([]string, []string) {
 	if len(desired) == 0 && len(actual) == 0 {
 		return nil, nil
@@ -1,8 +1,10 @@
 package health
 
 import (
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/nholik/swarm-sentinel/internal/compose"
 	"github.com/nholik/swarm-sentinel/internal/swarm"
@@ -161,6 +163,100 @@ func TestEvaluateStackHealth_GlobalModeReplicas(t *testing.T) {
 	}
 }
 
+func TestEvaluateStackHealth_ModeMismatch(t *testing.T) {
+	desired := compose.DesiredState{
+		Services: map[string]compose.DesiredService{
+			"agent": {Image: "agent:v1", Mode: "global"},
+			"api":   {Image: "api:v1", Mode: "replicated", Replicas: 2},
+		},
+	}
+	actual := &swarm.ActualState{
+		Services: map[string]swarm.ActualService{
+			"agent": {Name: "agent", Image: "agent:v1", Mode: "replicated", DesiredReplicas: 1, RunningReplicas: 1},
+			"api":   {Name: "api", Image: "api:v1", Mode: "replicated", DesiredReplicas: 2, RunningReplicas: 2},
+		},
+	}
+
+	report := EvaluateStackHealth(desired, actual, true)
+
+	agent := report.Services["agent"]
+	if agent.Status != StatusDegraded {
+		t.Fatalf("expected mode mismatch to degrade, got %s", agent.Status)
+	}
+	if !containsReason(agent.Reasons, "deploy mode mismatch: compose declares global but Swarm runs replicated") {
+		t.Fatalf("expected mode reason, got %v", agent.Reasons)
+	}
+	if !reflect.DeepEqual(agent.Drift, []DriftDetail{{Kind: DriftChanged, Resource: "service", Name: "mode", Desired: "global", Actual: "replicated"}}) {
+		t.Fatalf("unexpected mode drift: %+v", agent.Drift)
+	}
+	if len(agent.Reasons) != 1 {
+		t.Fatalf("expected replicas to follow Swarm's target on mismatch, got %v", agent.Reasons)
+	}
+	if api := report.Services["api"]; api.Status != StatusOK {
+		t.Fatalf("expected matching modes to be healthy, got %v", api.Reasons)
+	}
+}
+
+func TestEvaluateStackHealth_Jobs(t *testing.T) {
+	desired := compose.DesiredState{
+		Services: map[string]compose.DesiredService{
+			"migrate":  {Image: "app:v1", Mode: "replicated-job", Replicas: 1, MaxConcurrent: 1, TotalCompletions: 1},
+			"backfill": {Image: "app:v1", Mode: "replicated-job", Replicas: 2, MaxConcurrent: 2, TotalCompletions: 4},
+			"seed":     {Image: "app:v1", Mode: "replicated-job", Replicas: 1, MaxConcurrent: 1, TotalCompletions: 1},
+			"prune":    {Image: "app:v1", Mode: "global-job"},
+			"setup":    {Image: "app:v1", Mode: "replicated-job", Replicas: 1, MaxConcurrent: 1, TotalCompletions: 1, Configs: []string{"cfg"}, Secrets: []string{"db_pass"}},
+		},
+	}
+	actual := &swarm.ActualState{
+		Services: map[string]swarm.ActualService{
+			"migrate":  {Name: "migrate", Image: "app:v1", Mode: "replicated-job", CompletedTasks: 1, FailedTasks: 1, MaxConcurrent: 1, TotalCompletions: 1},
+			"backfill": {Name: "backfill", Image: "app:v1", Mode: "replicated-job", DesiredReplicas: 2, RunningReplicas: 1, CompletedTasks: 2, FailedTasks: 1, MaxConcurrent: 1, TotalCompletions: 4},
+			"seed":     {Name: "seed", Image: "app:v1", Mode: "replicated-job", DesiredReplicas: 1, FailedTasks: 3, MaxConcurrent: 1, TotalCompletions: 1},
+			"prune":    {Name: "prune", Image: "app:v1", Mode: "global-job", DesiredReplicas: 3, CompletedTasks: 3},
+			"setup":    {Name: "setup", Image: "app:v1", Mode: "replicated-job", CompletedTasks: 1, MaxConcurrent: 1, TotalCompletions: 1, Configs: []string{"cfg"}, Secrets: []string{"db_pass"}},
+		},
+	}
+
+	report := EvaluateStackHealth(desired, actual, true)
+
+	migrate := report.Services["migrate"]
+	if migrate.Status != StatusOK || len(migrate.Reasons) != 0 {
+		t.Fatalf("expected completed job to be healthy, got %s %v", migrate.Status, migrate.Reasons)
+	}
+	if !reflect.DeepEqual(migrate.Job, &JobHealth{State: JobSucceeded, Completed: 1, Total: 1, Failed: 1, Attempts: 2}) {
+		t.Fatalf("unexpected job health: %+v", migrate.Job)
+	}
+
+	backfill := report.Services["backfill"]
+	if backfill.Status != StatusDegraded || backfill.Job == nil || backfill.Job.State != JobRunning {
+		t.Fatalf("expected running job with failures to degrade, got %s %+v", backfill.Status, backfill.Job)
+	}
+	if !containsReason(backfill.Reasons, "job running: 2/4 completed, 1 failed attempts") {
+		t.Fatalf("expected job progress reason, got %v", backfill.Reasons)
+	}
+	if !hasDrift(backfill.Drift, DriftChanged, "job", "max_concurrent") {
+		t.Fatalf("expected max_concurrent drift, got %+v", backfill.Drift)
+	}
+
+	seed := report.Services["seed"]
+	if seed.Status != StatusFailed || seed.Job == nil || seed.Job.State != JobFailed {
+		t.Fatalf("expected failed job, got %s %+v", seed.Status, seed.Job)
+	}
+	if !containsReason(seed.Reasons, "job failed: 0/1 completed, 3 failed attempts") {
+		t.Fatalf("expected job failure reason, got %v", seed.Reasons)
+	}
+
+	prune := report.Services["prune"]
+	if prune.Status != StatusOK || prune.Job == nil || prune.Job.State != JobSucceeded || prune.Job.Total != 3 {
+		t.Fatalf("expected global job to succeed on every node, got %s %+v", prune.Status, prune.Job)
+	}
+
+	setup := report.Services["setup"]
+	if setup.Status != StatusOK || len(setup.Drift) != 0 || setup.Job == nil || setup.Job.State != JobSucceeded {
+		t.Fatalf("expected completed job with configs and secrets to be healthy, got %s %v %+v", setup.Status, setup.Reasons, setup.Drift)
+	}
+}
+
 func TestEvaluateStackHealth_ImageMismatch(t *testing.T) {
 	desired := compose.DesiredState{
 		Services: map[string]compose.DesiredService{
@@ -184,6 +280,57 @@ func TestEvaluateStackHealth_ImageMismatch(t *testing.T) {
 	}
 }
 
+func TestEvaluateStackHealth_ImageDigestDrift(t *testing.T) {
+	const (
+		pinned = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		other  = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	)
+	desired := compose.DesiredState{
+		Services: map[string]compose.DesiredService{
+			"api":    {Image: "app:v1@" + pinned, Mode: "replicated", Replicas: 1},
+			"web":    {Image: "nginx:1.27", Mode: "replicated", Replicas: 2},
+			"worker": {Image: "worker:v1", Mode: "replicated", Replicas: 2},
+			"cron":   {Image: "cron:v1@" + pinned, Mode: "replicated", Replicas: 1},
+		},
+	}
+	actual := &swarm.ActualState{
+		Services: map[string]swarm.ActualService{
+			"api":    {Name: "api", Image: "app:v1@" + other, Mode: "replicated", DesiredReplicas: 1, RunningReplicas: 1, TaskDigests: []string{other}},
+			"web":    {Name: "web", Image: "nginx:1.27@" + other, Mode: "replicated", DesiredReplicas: 2, RunningReplicas: 2, TaskDigests: []string{pinned, other}},
+			"worker": {Name: "worker", Image: "worker:v1@" + other, Mode: "replicated", DesiredReplicas: 2, RunningReplicas: 2, UpdateState: "updating", TaskDigests: []string{pinned, other}},
+			"cron":   {Name: "cron", Image: "cron:v1@" + pinned, Mode: "replicated", DesiredReplicas: 1, RunningReplicas: 1, TaskDigests: []string{pinned}},
+		},
+	}
+
+	report := EvaluateStackHealth(desired, actual, true)
+
+	api := report.Services["api"]
+	if api.Status != StatusDegraded {
+		t.Fatalf("expected pinned digest mismatch to degrade, got %s", api.Status)
+	}
+	if !containsReason(api.Reasons, "image digest mismatch: want sha256:111111111111 got sha256:222222222222") {
+		t.Fatalf("expected digest reason, got %v", api.Reasons)
+	}
+	if !reflect.DeepEqual(api.Drift, []DriftDetail{{Kind: DriftImageDigest, Resource: "image", Name: "service", Desired: pinned, Actual: other}}) {
+		t.Fatalf("unexpected digest drift: %+v", api.Drift)
+	}
+
+	web := report.Services["web"]
+	if web.Status != StatusDegraded || !containsReason(web.Reasons, "running tasks use mixed image digests: sha256:111111111111, sha256:222222222222") {
+		t.Fatalf("expected mixed task digests to degrade, got %s %v", web.Status, web.Reasons)
+	}
+	if !hasDrift(web.Drift, DriftImageDigest, "image", "tasks") {
+		t.Fatalf("expected task digest drift, got %+v", web.Drift)
+	}
+
+	if worker := report.Services["worker"]; worker.Status != StatusOK {
+		t.Fatalf("expected mixed digests to be ignored during an update, got %v", worker.Reasons)
+	}
+	if cron := report.Services["cron"]; cron.Status != StatusOK {
+		t.Fatalf("expected matching pinned digest to be healthy, got %v", cron.Reasons)
+	}
+}
+
 func TestEvaluateStackHealth_ConfigSecretDrift(t *testing.T) {
 	desired := compose.DesiredState{
 		Services: map[string]compose.DesiredService{
@@ -284,6 +431,543 @@ func TestEvaluateStackHealth_UpdateInProgressStillAlertsOnZeroReplicas(t *testin
 	}
 }
 
+func TestEvaluateStackHealth_PortDrift(t *testing.T) {
+	desired := compose.DesiredState{
+		Services: map[string]compose.DesiredService{
+			"web": {
+				Image: "app:v1", Mode: "replicated", Replicas: 1, EndpointMode: "vip",
+				Ports: []compose.PortMapping{
+					{Target: 80, Published: 8080, Protocol: "tcp", Mode: "ingress"},
+					{Target: 443, Published: 8443, Protocol: "tcp", Mode: "ingress"},
+					{Target: 9000, Protocol: "tcp", Mode: "ingress"},
+				},
+			},
+			"api": {
+				Image: "app:v1", Mode: "replicated", Replicas: 1, EndpointMode: "vip",
+				Ports: []compose.PortMapping{{Target: 53, Published: 53, Protocol: "udp", Mode: "ingress"}},
+			},
+		},
+	}
+	actual := &swarm.ActualState{
+		Services: map[string]swarm.ActualService{
+			"web": {
+				Name: "web", Image: "app:v1", RunningReplicas: 1, EndpointMode: "vip",
+				Ports: []swarm.PortMapping{
+					{Target: 80, Published: 8081, Protocol: "tcp", Mode: "ingress"},
+					{Target: 443, Published: 8443, Protocol: "tcp", Mode: "ingress"},
+					{Target: 9000, Published: 30001, Protocol: "tcp", Mode: "ingress"},
+				},
+			},
+			"api": {
+				Name: "api", Image: "app:v1", RunningReplicas: 1, EndpointMode: "dnsrr",
+				Ports: []swarm.PortMapping{
+					{Target: 53, Published: 53, Protocol: "udp", Mode: "ingress"},
+					{Target: 8080, Published: 8080, Protocol: "tcp", Mode: "ingress"},
+				},
+			},
+		},
+	}
+
+	health := EvaluateStackHealth(desired, actual, true)
+
+	web := health.Services["web"]
+	if web.Status != StatusFailed {
+		t.Fatalf("expected changed published port to fail, got %s", web.Status)
+	}
+	if len(web.Drift) != 1 || !hasDrift(web.Drift, DriftChanged, "port", "80/tcp") {
+		t.Fatalf("expected only port 80 to drift, got %+v", web.Drift)
+	}
+	if web.Drift[0].Desired != "8080:80/tcp" || web.Drift[0].Actual != "8081:80/tcp" {
+		t.Fatalf("unexpected port change detail: %+v", web.Drift[0])
+	}
+
+	api := health.Services["api"]
+	if api.Status != StatusDegraded {
+		t.Fatalf("expected extra port and endpoint mode to degrade, got %s", api.Status)
+	}
+	if !hasDrift(api.Drift, DriftExtra, "port", "8080:8080/tcp") || !hasDrift(api.Drift, DriftChanged, "endpoint", "mode") {
+		t.Fatalf("expected extra port and endpoint mode drift, got %+v", api.Drift)
+	}
+	if !containsReason(api.Reasons, "endpoint mode mismatch: want vip got dnsrr") {
+		t.Fatalf("expected endpoint mode reason, got %v", api.Reasons)
+	}
+
+	worker := EvaluateStackHealth(compose.DesiredState{Services: map[string]compose.DesiredService{
+		"worker": {Image: "app:v1", Mode: "global", Ports: []compose.PortMapping{{Target: 9100, Published: 9100, Protocol: "tcp", Mode: "host"}}},
+	}}, &swarm.ActualState{Services: map[string]swarm.ActualService{
+		"worker": {Name: "worker", Image: "app:v1", DesiredReplicas: 1, RunningReplicas: 1},
+	}}, true).Services["worker"]
+	if worker.Status != StatusFailed || !hasDrift(worker.Drift, DriftMissing, "port", "9100:9100/tcp (host)") {
+		t.Fatalf("expected missing host port to fail, got %s %+v", worker.Status, worker.Drift)
+	}
+}
+
+func TestEvaluateStackHealth_EnvDrift(t *testing.T) {
+	desired := compose.DesiredState{
+		Services: map[string]compose.DesiredService{
+			"api": {
+				Image: "app:v1", Mode: "replicated", Replicas: 1,
+				Environment: map[string]string{
+					"LOG_LEVEL":    compose.HashEnvValue("info"),
+					"DATABASE_URL": compose.HashEnvValue("postgres://db"),
+					"FEATURE_X":    compose.HashEnvValue("on"),
+					"BUILD_ID":     compose.HashEnvValue("41"),
+				},
+				IgnoreEnv: []string{"BUILD_ID", "OTEL_*"},
+			},
+		},
+	}
+	actual := &swarm.ActualState{
+		Services: map[string]swarm.ActualService{
+			"api": {
+				Name: "api", Image: "app:v1", RunningReplicas: 1,
+				Environment: map[string]string{
+					"LOG_LEVEL":         compose.HashEnvValue("debug"),
+					"DATABASE_URL":      compose.HashEnvValue("postgres://db"),
+					"DEBUG":             compose.HashEnvValue("1"),
+					"BUILD_ID":          compose.HashEnvValue("42"),
+					"OTEL_SERVICE_NAME": compose.HashEnvValue("api"),
+				},
+			},
+		},
+	}
+
+	health := EvaluateStackHealth(desired, actual, true)
+	api := health.Services["api"]
+
+	if api.Status != StatusDegraded {
+		t.Fatalf("expected env drift to degrade, got %s", api.Status)
+	}
+	if len(api.Drift) != 3 ||
+		!hasDrift(api.Drift, DriftMissing, "env", "FEATURE_X") ||
+		!hasDrift(api.Drift, DriftExtra, "env", "DEBUG") ||
+		!hasDrift(api.Drift, DriftChanged, "env", "LOG_LEVEL") {
+		t.Fatalf("unexpected env drift: %+v", api.Drift)
+	}
+	for _, reason := range api.Reasons {
+		if strings.Contains(reason, "debug") || strings.Contains(reason, "info") {
+			t.Fatalf("expected values to stay out of reasons, got %v", api.Reasons)
+		}
+	}
+	for _, detail := range api.Drift {
+		if detail.Desired != "" || detail.Actual != "" {
+			t.Fatalf("expected no values in drift details, got %+v", detail)
+		}
+	}
+}
+
+func TestEvaluateStackHealth_EnvDriftSeverity(t *testing.T) {
+	env := map[string]string{
+		"LOG_LEVEL": compose.HashEnvValue("info"),
+		"FEATURE_X": compose.HashEnvValue("on"),
+	}
+	desired := compose.DesiredState{
+		Services: map[string]compose.DesiredService{
+			"removed": {Image: "app:v1", Mode: "replicated", Replicas: 1, Environment: env},
+			"changed": {Image: "app:v1", Mode: "replicated", Replicas: 1, Environment: env},
+		},
+	}
+	actual := &swarm.ActualState{
+		Services: map[string]swarm.ActualService{
+			"removed": {
+				Name: "removed", Image: "app:v1", RunningReplicas: 1,
+				Environment: map[string]string{"LOG_LEVEL": compose.HashEnvValue("info")},
+			},
+			"changed": {
+				Name: "changed", Image: "app:v1", RunningReplicas: 1,
+				Environment: map[string]string{"LOG_LEVEL": compose.HashEnvValue("info"), "FEATURE_X": compose.HashEnvValue("off")},
+			},
+		},
+	}
+
+	report := EvaluateStackHealth(desired, actual, true)
+
+	removed, changed := report.Services["removed"], report.Services["changed"]
+	if !hasDrift(removed.Drift, DriftMissing, "env", "FEATURE_X") || !hasDrift(changed.Drift, DriftChanged, "env", "FEATURE_X") {
+		t.Fatalf("unexpected env drift: %+v %+v", removed.Drift, changed.Drift)
+	}
+	if removed.Status != StatusDegraded || changed.Status != StatusDegraded {
+		t.Fatalf("expected removed and changed variables to degrade alike, got %s and %s", removed.Status, changed.Status)
+	}
+}
+
+func TestEvaluateStackHealth_LabelDrift(t *testing.T) {
+	desired := compose.DesiredState{
+		Services: map[string]compose.DesiredService{
+			"web": {
+				Image: "app:v1", Mode: "replicated", Replicas: 1,
+				Labels: map[string]string{
+					"traefik.http.routers.web.rule": "Host(`example.com`)",
+					"traefik.enable":                "true",
+					"team":                          "platform",
+				},
+				ContainerLabels: map[string]string{"traefik.docker.network": "proxy"},
+			},
+		},
+	}
+	actual := &swarm.ActualState{
+		Services: map[string]swarm.ActualService{
+			"web": {
+				Name: "web", Image: "app:v1", RunningReplicas: 1,
+				Labels: map[string]string{
+					"traefik.http.routers.web.rule": "Host(`old.example.com`)",
+					"traefik.enable":                "true",
+					"traefik.http.middlewares.x":    "y",
+					"team":                          "infra",
+				},
+			},
+		},
+	}
+
+	health := EvaluateStackHealth(desired, actual, true, WithLabelPrefixes("traefik."))
+	web := health.Services["web"]
+
+	if web.Status != StatusDegraded {
+		t.Fatalf("expected label drift to degrade, got %s", web.Status)
+	}
+	if len(web.Drift) != 3 ||
+		!hasDrift(web.Drift, DriftLabel, "label", "traefik.http.routers.web.rule") ||
+		!hasDrift(web.Drift, DriftLabel, "label", "traefik.http.middlewares.x") ||
+		!hasDrift(web.Drift, DriftLabel, "container_label", "traefik.docker.network") {
+		t.Fatalf("unexpected label drift: %+v", web.Drift)
+	}
+	if !containsReason(web.Reasons, "missing container label: traefik.docker.network") {
+		t.Fatalf("expected container label reason, got %v", web.Reasons)
+	}
+
+	all := EvaluateStackHealth(desired, actual, true).Services["web"]
+	if !hasDrift(all.Drift, DriftLabel, "label", "team") {
+		t.Fatalf("expected every label to be compared without prefixes, got %+v", all.Drift)
+	}
+}
+
+func TestEvaluateStackHealth_ResourceDrift(t *testing.T) {
+	desired := compose.DesiredState{
+		Services: map[string]compose.DesiredService{
+			"api": {
+				Image: "app:v1", Mode: "replicated", Replicas: 1,
+				Resources: compose.Resources{
+					Limits:       compose.ResourceSpec{NanoCPUs: 100000001, MemoryBytes: 512 << 20},
+					Reservations: compose.ResourceSpec{MemoryBytes: 128 << 20, Generic: map[string]int64{"gpu": 1}},
+				},
+			},
+		},
+	}
+	actual := &swarm.ActualState{
+		Services: map[string]swarm.ActualService{
+			"api": {
+				Name: "api", Image: "app:v1", RunningReplicas: 1,
+				Resources: swarm.Resources{
+					Limits:       swarm.ResourceSpec{NanoCPUs: 100000000, MemoryBytes: 1024 << 20, Pids: 200},
+					Reservations: swarm.ResourceSpec{MemoryBytes: 128 << 20},
+				},
+			},
+		},
+	}
+
+	api := EvaluateStackHealth(desired, actual, true).Services["api"]
+
+	if api.Status != StatusDegraded {
+		t.Fatalf("expected resource drift to degrade, got %s", api.Status)
+	}
+	want := []DriftDetail{
+		{Kind: DriftChanged, Resource: "resources", Name: "limits.memory", Desired: "536870912", Actual: "1073741824"},
+		{Kind: DriftChanged, Resource: "resources", Name: "limits.pids", Actual: "200"},
+		{Kind: DriftChanged, Resource: "resources", Name: "reservations.generic_resources.gpu", Desired: "1"},
+	}
+	if !reflect.DeepEqual(api.Drift, want) {
+		t.Fatalf("unexpected resource drift: %+v", api.Drift)
+	}
+}
+
+func TestEvaluateStackHealth_PlacementDrift(t *testing.T) {
+	desired := compose.DesiredState{
+		Services: map[string]compose.DesiredService{
+			"api": {
+				Image: "app:v1", Mode: "replicated", Replicas: 1,
+				Placement: compose.Placement{
+					Constraints:        []string{"node.labels.pool == web", "node.role == worker"},
+					Preferences:        []string{"node.labels.zone"},
+					MaxReplicasPerNode: 2,
+				},
+			},
+		},
+	}
+	actual := &swarm.ActualState{
+		Services: map[string]swarm.ActualService{
+			"api": {
+				Name: "api", Image: "app:v1", RunningReplicas: 1,
+				Placement: swarm.Placement{
+					Constraints: []string{"node.labels.pool==batch", "node.role==worker"},
+					Preferences: []string{"node.labels.zone"},
+				},
+			},
+		},
+	}
+
+	api := EvaluateStackHealth(desired, actual, true).Services["api"]
+
+	if api.Status != StatusDegraded {
+		t.Fatalf("expected placement drift to degrade, got %s", api.Status)
+	}
+	want := []DriftDetail{
+		{Kind: DriftPlacement, Resource: "placement", Name: "constraints",
+			Desired: "node.labels.pool==web, node.role==worker", Actual: "node.labels.pool==batch, node.role==worker"},
+		{Kind: DriftPlacement, Resource: "placement", Name: "max_replicas_per_node", Desired: "2"},
+	}
+	if !reflect.DeepEqual(api.Drift, want) {
+		t.Fatalf("unexpected placement drift: %+v", api.Drift)
+	}
+}
+
+func TestEvaluateStackHealth_NetworkDrift(t *testing.T) {
+	desired := compose.DesiredState{
+		Services: map[string]compose.DesiredService{
+			"api": {
+				Image: "app:v1", Mode: "replicated", Replicas: 1,
+				Networks: []compose.NetworkAttachment{
+					{Name: "prod_backend", Aliases: []string{"api", "internal-api"}},
+					{Name: "prod_default", Aliases: []string{"api"}},
+				},
+			},
+			"worker": {Image: "worker:v1", Mode: "replicated", Replicas: 1},
+		},
+	}
+	actual := &swarm.ActualState{
+		Services: map[string]swarm.ActualService{
+			"api": {
+				Name: "api", Image: "app:v1", RunningReplicas: 1,
+				Networks: []swarm.NetworkAttachment{
+					{Name: "prod_backend", Aliases: []string{"api"}},
+					{Name: "shared"},
+				},
+			},
+			"worker": {
+				Name: "worker", Image: "worker:v1", RunningReplicas: 1,
+				Networks: []swarm.NetworkAttachment{{Name: "prod_default"}},
+			},
+		},
+	}
+
+	report := EvaluateStackHealth(desired, actual, true)
+
+	api := report.Services["api"]
+	if api.Status != StatusFailed {
+		t.Fatalf("expected missing network to fail, got %s", api.Status)
+	}
+	want := []DriftDetail{
+		{Kind: DriftMissing, Resource: "network", Name: "prod_default"},
+		{Kind: DriftExtra, Resource: "network", Name: "shared"},
+		{Kind: DriftChanged, Resource: "network", Name: "prod_backend", Desired: "api, internal-api", Actual: "api"},
+	}
+	if !reflect.DeepEqual(api.Drift, want) {
+		t.Fatalf("unexpected network drift: %+v", api.Drift)
+	}
+	if worker := report.Services["worker"]; worker.Status != StatusOK {
+		t.Fatalf("expected unresolved desired networks to be skipped, got %s: %v", worker.Status, worker.Reasons)
+	}
+}
+
+func TestEvaluateStackHealth_PolicyDrift(t *testing.T) {
+	defaults := compose.UpdatePolicy{Parallelism: 1, FailureAction: "pause", Monitor: 5 * time.Second, Order: "stop-first"}
+	desired := compose.DesiredState{
+		Services: map[string]compose.DesiredService{
+			"api": {
+				Image: "app:v1", Mode: "replicated", Replicas: 1,
+				UpdateConfig:   defaults,
+				RollbackConfig: defaults,
+				RestartPolicy:  compose.RestartPolicy{Condition: "any", Delay: 5 * time.Second},
+			},
+		},
+	}
+	actual := &swarm.ActualState{
+		Services: map[string]swarm.ActualService{
+			"api": {
+				Name: "api", Image: "app:v1", RunningReplicas: 1,
+				UpdateConfig:   swarm.UpdatePolicy{Parallelism: 0, FailureAction: "continue", Monitor: 5 * time.Second, Order: "stop-first"},
+				RollbackConfig: swarm.UpdatePolicy(defaults),
+				RestartPolicy:  swarm.RestartPolicy{Condition: "none", Delay: 5 * time.Second},
+			},
+		},
+	}
+
+	api := EvaluateStackHealth(desired, actual, true).Services["api"]
+	if api.Status != StatusDegraded {
+		t.Fatalf("expected policy drift to degrade by default, got %s", api.Status)
+	}
+	want := []DriftDetail{
+		{Kind: DriftPolicy, Resource: "update_config", Name: "parallelism", Desired: "1", Actual: "0"},
+		{Kind: DriftPolicy, Resource: "update_config", Name: "failure_action", Desired: "pause", Actual: "continue"},
+		{Kind: DriftPolicy, Resource: "restart_policy", Name: "condition", Desired: "any", Actual: "none"},
+	}
+	if !reflect.DeepEqual(api.Drift, want) {
+		t.Fatalf("unexpected policy drift: %+v", api.Drift)
+	}
+
+	if got := EvaluateStackHealth(desired, actual, true, WithPolicySeverity(StatusFailed)).Services["api"]; got.Status != StatusFailed {
+		t.Fatalf("expected configured severity to apply, got %s", got.Status)
+	}
+	informational := EvaluateStackHealth(desired, actual, true, WithPolicySeverity(StatusOK)).Services["api"]
+	if informational.Status != StatusOK || len(informational.Drift) != len(want) {
+		t.Fatalf("expected drift without a status change, got %s with %+v", informational.Status, informational.Drift)
+	}
+}
+
+func TestEvaluateStackHealth_HealthcheckDrift(t *testing.T) {
+	desired := compose.DesiredState{
+		Services: map[string]compose.DesiredService{
+			"api": {
+				Image: "app:v1", Mode: "replicated", Replicas: 1,
+				Healthcheck: &compose.Healthcheck{
+					Test:     []string{"CMD-SHELL", "curl -f http://localhost/"},
+					Interval: time.Minute,
+					Retries:  3,
+				},
+			},
+			"web": {
+				Image: "web:v1", Mode: "replicated", Replicas: 1,
+				Healthcheck: &compose.Healthcheck{Test: []string{"CMD", "wget", "-q", "http://localhost/"}, Interval: 30 * time.Second},
+			},
+			"worker": {Image: "worker:v1", Mode: "replicated", Replicas: 1},
+		},
+	}
+	actual := &swarm.ActualState{
+		Services: map[string]swarm.ActualService{
+			"api": {
+				Name: "api", Image: "app:v1", RunningReplicas: 1,
+				Healthcheck: &swarm.Healthcheck{
+					Test:     []string{"CMD", "/bin/sh", "-c", "curl -f http://localhost/"},
+					Interval: 60 * time.Second,
+					Retries:  10,
+				},
+			},
+			"web": {
+				Name: "web", Image: "web:v1", RunningReplicas: 1,
+				Healthcheck: &swarm.Healthcheck{Test: []string{"NONE"}},
+			},
+			"worker": {
+				Name: "worker", Image: "worker:v1", RunningReplicas: 1,
+				Healthcheck: &swarm.Healthcheck{},
+			},
+		},
+	}
+
+	report := EvaluateStackHealth(desired, actual, true)
+
+	api := report.Services["api"]
+	if api.Status != StatusDegraded {
+		t.Fatalf("expected weakened healthcheck to degrade, got %s", api.Status)
+	}
+	want := []DriftDetail{{Kind: DriftChanged, Resource: "healthcheck", Name: "retries", Desired: "3", Actual: "10"}}
+	if !reflect.DeepEqual(api.Drift, want) {
+		t.Fatalf("expected only retries drift, got %+v", api.Drift)
+	}
+
+	web := report.Services["web"]
+	want = []DriftDetail{{Kind: DriftChanged, Resource: "healthcheck", Name: "test", Desired: "CMD wget -q http://localhost/", Actual: "NONE"}}
+	if !reflect.DeepEqual(web.Drift, want) {
+		t.Fatalf("expected disabled healthcheck drift, got %+v", web.Drift)
+	}
+
+	if worker := report.Services["worker"]; worker.Status != StatusOK {
+		t.Fatalf("expected an empty healthcheck to match an inherited one, got %v", worker.Reasons)
+	}
+}
+
+func TestEvaluateStackHealth_MountDrift(t *testing.T) {
+	desired := compose.DesiredState{
+		Services: map[string]compose.DesiredService{
+			"db": {
+				Image: "postgres:16", Mode: "replicated", Replicas: 1,
+				Mounts: []compose.Mount{
+					{Type: "volume", Source: "prod_backups", Target: "/backups"},
+					{Type: "bind", Source: "init", Target: "/docker-entrypoint-initdb.d", ReadOnly: true},
+					{Type: "bind", Source: "/etc/ssl/certs", Target: "/etc/ssl/certs", ReadOnly: true},
+					{Type: "volume", Source: "prod_data", Target: "/var/lib/postgresql/data"},
+				},
+			},
+			"web": {Image: "web:v1", Mode: "replicated", Replicas: 1},
+		},
+	}
+	actual := &swarm.ActualState{
+		Services: map[string]swarm.ActualService{
+			"db": {
+				Name: "db", Image: "postgres:16", RunningReplicas: 1,
+				Mounts: []swarm.Mount{
+					{Type: "bind", Source: "/srv/stacks/prod/init", Target: "/docker-entrypoint-initdb.d", ReadOnly: true},
+					{Type: "bind", Source: "/etc/ssl/certs", Target: "/etc/ssl/certs"},
+					{Type: "tmpfs", Target: "/tmp"},
+					{Type: "volume", Target: "/var/lib/postgresql/data"},
+				},
+			},
+			"web": {
+				Name: "web", Image: "web:v1", RunningReplicas: 1,
+				Mounts: []swarm.Mount{{Type: "tmpfs", Target: "/tmp"}},
+			},
+		},
+	}
+
+	report := EvaluateStackHealth(desired, actual, true)
+
+	db := report.Services["db"]
+	if db.Status != StatusFailed {
+		t.Fatalf("expected missing mount to fail, got %s", db.Status)
+	}
+	want := []DriftDetail{
+		{Kind: DriftMissing, Resource: "mount", Name: "/backups"},
+		{Kind: DriftExtra, Resource: "mount", Name: "/tmp"},
+		{Kind: DriftChanged, Resource: "mount", Name: "/etc/ssl/certs",
+			Desired: "bind /etc/ssl/certs:/etc/ssl/certs:ro", Actual: "bind /etc/ssl/certs:/etc/ssl/certs"},
+		{Kind: DriftChanged, Resource: "mount", Name: "/var/lib/postgresql/data",
+			Desired: "volume prod_data:/var/lib/postgresql/data", Actual: "volume /var/lib/postgresql/data"},
+	}
+	if !reflect.DeepEqual(db.Drift, want) {
+		t.Fatalf("unexpected mount drift: %+v", db.Drift)
+	}
+	if web := report.Services["web"]; web.Status != StatusOK {
+		t.Fatalf("expected unresolved desired mounts to be skipped, got %v", web.Reasons)
+	}
+}
+
+func TestEvaluateStackHealth_RuntimeDrift(t *testing.T) {
+	desired := compose.DesiredState{
+		Services: map[string]compose.DesiredService{
+			"api": {
+				Image: "app:v1", Mode: "replicated", Replicas: 1,
+				Command:         []string{"serve"},
+				User:            "app",
+				StopGracePeriod: 30 * time.Second,
+			},
+		},
+	}
+	actual := &swarm.ActualState{
+		Services: map[string]swarm.ActualService{
+			"api": {
+				Name: "api", Image: "app:v1", RunningReplicas: 1,
+				Command:         []string{"serve", "--log-level", "debug"},
+				User:            "app",
+				StopGracePeriod: 30 * time.Second,
+				Hostname:        "debug-box",
+			},
+		},
+	}
+
+	api := EvaluateStackHealth(desired, actual, true).Services["api"]
+	if api.Status != StatusDegraded {
+		t.Fatalf("expected hot-patched command to degrade, got %s", api.Status)
+	}
+	want := []DriftDetail{
+		{Kind: DriftChanged, Resource: "container", Name: "command", Desired: "serve", Actual: "serve --log-level debug"},
+		{Kind: DriftChanged, Resource: "container", Name: "hostname", Actual: "debug-box"},
+	}
+	if !reflect.DeepEqual(api.Drift, want) {
+		t.Fatalf("unexpected runtime drift: %+v", api.Drift)
+	}
+	if !containsReason(api.Reasons, "container command changed") {
+		t.Fatalf("expected command reason, got %v", api.Reasons)
+	}
+}
+
 func containsReason(reasons []string, value string) bool {
 	for _, reason := range reasons {
 		if strings.Contains(reason, value) {
@@ -16,27 +16,68 @@ const (
 	DriftMissing      DriftKind = "MISSING"
 	DriftExtra        DriftKind = "EXTRA"
 	DriftExtraService DriftKind = "EXTRA_SERVICE"
+	DriftChanged      DriftKind = "CHANGED"
+	// DriftLabel reports a service or container label that was added,
+	// removed or changed. Desired or Actual is empty when the label is
+	// missing on that side.
+	DriftLabel DriftKind = "LABEL"
+	// DriftPlacement reports placement constraints, preferences or
+	// max_replicas_per_node that differ from compose.
+	DriftPlacement DriftKind = "PLACEMENT"
+	// DriftPolicy reports an update_config, rollback_config or
+	// restart_policy setting that differs from compose.
+	DriftPolicy DriftKind = "POLICY"
+	// DriftImageDigest reports image content that differs while tags match:
+	// a digest pinned in compose that Swarm does not run, or running tasks
+	// on a digest other than the one in the service spec.
+	DriftImageDigest DriftKind = "IMAGE_DIGEST"
 )
 
-// DriftDetail describes a single drift finding.
+// DriftDetail describes a single drift finding. Desired and Actual are set
+// for DriftChanged, DriftLabel, DriftPlacement, DriftPolicy and
+// DriftImageDigest findings.
 type DriftDetail struct {
 	Kind     DriftKind
 	Resource string
 	Name     string
+	Desired  string
+	Actual   string
 }
 
 // ServiceHealth captures health evaluation output for a service.
 type ServiceHealth struct {
-	Name            string
-	Status          ServiceStatus
-	Reasons         []string
-	Drift           []DriftDetail
-	DesiredImage    string
-	ActualImage     string
-	DesiredReplicas int
-	RunningReplicas int
-	ConsecutiveCycles int
+	Name               string
+	Status             ServiceStatus
+	Reasons            []string
+	Drift              []DriftDetail
+	DesiredImage       string
+	ActualImage        string
+	DesiredReplicas    int
+	RunningReplicas    int
+	ConsecutiveCycles  int
 	LastNotifiedStatus ServiceStatus
+	Job                *JobHealth
+}
+
+// JobState is the progress of a replicated or global job.
+type JobState string
+
+const (
+	JobRunning   JobState = "running"
+	JobSucceeded JobState = "succeeded"
+	JobFailed    JobState = "failed"
+)
+
+// JobHealth reports the current run of a job service. Jobs are judged by
+// completed tasks rather than running replicas. Attempts counts every task
+// started in the run, including the failed ones that Swarm retried.
+type JobHealth struct {
+	State     JobState
+	Completed int
+	Total     int
+	Running   int
+	Failed    int
+	Attempts  int
 }
 
 // StackHealth summarizes the health for a stack.
@@ -0,0 +1,26 @@
+package health
+
+// EvaluateOption customizes EvaluateStackHealth.
+type EvaluateOption func(*evaluateOptions)
+
+type evaluateOptions struct {
+	labelPrefixes  []string
+	policySeverity ServiceStatus
+}
+
+// WithLabelPrefixes limits label drift detection to label keys starting with
+// one of prefixes. Without it every label is compared.
+func WithLabelPrefixes(prefixes ...string) EvaluateOption {
+	return func(o *evaluateOptions) {
+		o.labelPrefixes = prefixes
+	}
+}
+
+// WithPolicySeverity sets the status a service is given when its update,
+// rollback or restart policy drifts from compose. StatusOK reports the drift
+// without affecting health; the default is StatusDegraded.
+func WithPolicySeverity(status ServiceStatus) EvaluateOption {
+	return func(o *evaluateOptions) {
+		o.policySeverity = status
+	}
+}
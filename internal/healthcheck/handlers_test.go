@@ -6,6 +6,8 @@ import (
 	"net/http/httptest"
 	"testing"
 	"time"
+
+	"github.com/nholik/swarm-sentinel/internal/compose"
 )
 
 func TestHealthHandlerHealthy(t *testing.T) {
@@ -74,3 +76,53 @@ func TestReadyHandler(t *testing.T) {
 		t.Fatalf("expected 200 after ready, got %d", rec.Code)
 	}
 }
+
+func TestHealthHandlerReportsCachedDesiredState(t *testing.T) {
+	tracker := NewTracker()
+	tracker.RecordCycle(10*time.Millisecond, 2)
+	cachedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tracker.SetDesiredStateCached("prod", cachedAt)
+	tracker.SetDesiredStateCached("staging", cachedAt)
+	tracker.SetDesiredStateCached("staging", time.Time{})
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	rec := httptest.NewRecorder()
+	HealthHandler(tracker, 5*time.Second)(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var payload Snapshot
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(payload.CachedDesiredState) != 1 || !payload.CachedDesiredState["prod"].Equal(cachedAt) {
+		t.Fatalf("expected only prod to be flagged, got %+v", payload.CachedDesiredState)
+	}
+}
+
+func TestHealthHandlerReportsParseErrors(t *testing.T) {
+	tracker := NewTracker()
+	tracker.RecordCycle(10*time.Millisecond, 1)
+	tracker.SetParseError("prod", &compose.ParseError{File: "compose.yml", Line: 4, Column: 5, Service: "web", Field: "ports", Message: "must be a array"})
+	tracker.SetParseError("staging", &compose.ParseError{Message: "compose has no services"})
+	tracker.SetParseError("staging", nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	rec := httptest.NewRecorder()
+	HealthHandler(tracker, 5*time.Second)(rec, req)
+
+	var payload struct {
+		ParseErrors map[string]map[string]any `json:"parse_errors"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	prod, ok := payload.ParseErrors["prod"]
+	if len(payload.ParseErrors) != 1 || !ok {
+		t.Fatalf("expected only prod to report a parse error, got %+v", payload.ParseErrors)
+	}
+	if prod["file"] != "compose.yml" || prod["line"] != float64(4) || prod["service"] != "web" || prod["field"] != "ports" {
+		t.Fatalf("unexpected parse error payload: %+v", prod)
+	}
+}
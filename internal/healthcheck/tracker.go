@@ -3,13 +3,30 @@ package healthcheck
 import (
 	"sync"
 	"time"
+
+	"github.com/nholik/swarm-sentinel/internal/compose"
 )
 
-// Snapshot describes the latest cycle timing details.
+// ParseErrorStatus is the /healthz view of a compose.ParseError.
+type ParseErrorStatus struct {
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Service string `json:"service,omitempty"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// Snapshot describes the latest cycle timing details. CachedDesiredState
+// lists stacks evaluated against a cached desired state because their compose
+// source could not be fetched, keyed by stack with the time it was cached.
+// ParseErrors lists stacks whose latest compose content failed to parse.
 type Snapshot struct {
-	LastCycleTime   *time.Time `json:"last_cycle_time"`
-	CycleDurationMS int64      `json:"cycle_duration_ms"`
-	StacksEvaluated int        `json:"stacks_evaluated"`
+	LastCycleTime      *time.Time                  `json:"last_cycle_time"`
+	CycleDurationMS    int64                       `json:"cycle_duration_ms"`
+	StacksEvaluated    int                         `json:"stacks_evaluated"`
+	CachedDesiredState map[string]time.Time        `json:"cached_desired_state,omitempty"`
+	ParseErrors        map[string]ParseErrorStatus `json:"parse_errors,omitempty"`
 }
 
 // Tracker records cycle timing for health endpoints.
@@ -19,6 +36,8 @@ type Tracker struct {
 	cycleDuration   time.Duration
 	stacksEvaluated int
 	ready           bool
+	cachedStacks    map[string]time.Time
+	parseErrors     map[string]ParseErrorStatus
 }
 
 // NewTracker constructs a new Tracker.
@@ -40,6 +59,49 @@ func (t *Tracker) RecordCycle(duration time.Duration, stacksEvaluated int) {
 	t.mu.Unlock()
 }
 
+// SetDesiredStateCached records whether stack is being evaluated against a
+// cached desired state. A zero cachedAt clears the flag.
+func (t *Tracker) SetDesiredStateCached(stack string, cachedAt time.Time) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if cachedAt.IsZero() {
+		delete(t.cachedStacks, stack)
+		return
+	}
+	if t.cachedStacks == nil {
+		t.cachedStacks = map[string]time.Time{}
+	}
+	t.cachedStacks[stack] = cachedAt
+}
+
+// SetParseError records the latest compose parse failure for stack. A nil
+// perr clears it.
+func (t *Tracker) SetParseError(stack string, perr *compose.ParseError) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if perr == nil {
+		delete(t.parseErrors, stack)
+		return
+	}
+	if t.parseErrors == nil {
+		t.parseErrors = map[string]ParseErrorStatus{}
+	}
+	t.parseErrors[stack] = ParseErrorStatus{
+		File:    perr.File,
+		Line:    perr.Line,
+		Column:  perr.Column,
+		Service: perr.Service,
+		Field:   perr.Field,
+		Message: perr.Message,
+	}
+}
+
 // Snapshot returns the current tracker snapshot.
 func (t *Tracker) Snapshot() Snapshot {
 	if t == nil {
@@ -53,10 +115,26 @@ func (t *Tracker) Snapshot() Snapshot {
 		value := t.lastCycle
 		last = &value
 	}
+	var cached map[string]time.Time
+	if len(t.cachedStacks) > 0 {
+		cached = make(map[string]time.Time, len(t.cachedStacks))
+		for stack, cachedAt := range t.cachedStacks {
+			cached[stack] = cachedAt
+		}
+	}
+	var parseErrors map[string]ParseErrorStatus
+	if len(t.parseErrors) > 0 {
+		parseErrors = make(map[string]ParseErrorStatus, len(t.parseErrors))
+		for stack, perr := range t.parseErrors {
+			parseErrors[stack] = perr
+		}
+	}
 	return Snapshot{
-		LastCycleTime:   last,
-		CycleDurationMS: int64(t.cycleDuration / time.Millisecond),
-		StacksEvaluated: t.stacksEvaluated,
+		LastCycleTime:      last,
+		CycleDurationMS:    int64(t.cycleDuration / time.Millisecond),
+		StacksEvaluated:    t.stacksEvaluated,
+		CachedDesiredState: cached,
+		ParseErrors:        parseErrors,
 	}
 }
 
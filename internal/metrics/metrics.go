@@ -16,6 +16,7 @@ type Metrics struct {
 	alertsTotal              *prometheus.CounterVec
 	dockerAPIErrorsTotal     prometheus.Counter
 	lastSuccessfulCycleGauge prometheus.Gauge
+	desiredStateCached       *prometheus.GaugeVec
 }
 
 // New initializes a Metrics registry with all collectors registered.
@@ -44,6 +45,10 @@ func New() *Metrics {
 			Name: "swarm_sentinel_last_successful_cycle_timestamp",
 			Help: "Unix timestamp of the last successful cycle.",
 		}),
+		desiredStateCached: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "swarm_sentinel_desired_state_cached",
+			Help: "1 when the stack is evaluated against a cached desired state because its compose source is unavailable.",
+		}, []string{"stack"}),
 	}
 
 	registry.MustRegister(
@@ -52,6 +57,7 @@ func New() *Metrics {
 		m.alertsTotal,
 		m.dockerAPIErrorsTotal,
 		m.lastSuccessfulCycleGauge,
+		m.desiredStateCached,
 	)
 
 	return m
@@ -104,3 +110,15 @@ func (m *Metrics) SetLastSuccessfulCycleTimestamp(t time.Time) {
 	}
 	m.lastSuccessfulCycleGauge.Set(float64(t.Unix()))
 }
+
+// SetDesiredStateCached flags whether the stack uses a cached desired state.
+func (m *Metrics) SetDesiredStateCached(stack string, cached bool) {
+	if m == nil {
+		return
+	}
+	value := 0.0
+	if cached {
+		value = 1
+	}
+	m.desiredStateCached.WithLabelValues(stack).Set(value)
+}
@@ -16,6 +16,7 @@ func TestMetricsUpdates(t *testing.T) {
 	m.IncAlertsTotal("alpha", "failed")
 	m.IncDockerAPIErrors()
 	m.SetLastSuccessfulCycleTimestamp(time.Unix(100, 0))
+	m.SetDesiredStateCached("alpha", true)
 
 	if got := testutil.ToFloat64(m.servicesTotal.WithLabelValues("alpha", "ok")); got != 3 {
 		t.Fatalf("expected ok services 3, got %v", got)
@@ -32,6 +33,9 @@ func TestMetricsUpdates(t *testing.T) {
 	if got := testutil.ToFloat64(m.lastSuccessfulCycleGauge); got != 100 {
 		t.Fatalf("expected last successful cycle 100, got %v", got)
 	}
+	if got := testutil.ToFloat64(m.desiredStateCached.WithLabelValues("alpha")); got != 1 {
+		t.Fatalf("expected desired state cached 1, got %v", got)
+	}
 	if count := testutil.CollectAndCount(m.cycleDurationSeconds); count == 0 {
 		t.Fatalf("expected cycle duration histogram to be collected")
 	}
@@ -21,13 +21,40 @@ func NewDryRunNotifier(logger zerolog.Logger, inner Notifier) *DryRunNotifier {
 // Notify implements Notifier.
 func (n *DryRunNotifier) Notify(_ context.Context, stack string, transitions []transition.ServiceTransition) error {
 	for _, change := range transitions {
-		n.logger.Info().
+		event := n.logger.Info().
 			Str("stack", stack).
 			Str("service", change.Name).
 			Str("previous_status", string(change.PreviousStatus)).
 			Str("current_status", string(change.CurrentStatus)).
-			Strs("reasons", change.Reasons).
-			Msg("[DRY-RUN] Would notify")
+			Strs("reasons", change.Reasons)
+		if change.DesiredRevision != nil {
+			event = event.Str("desired_commit", change.DesiredRevision.Commit)
+		}
+		event.Msg("[DRY-RUN] Would notify")
 	}
 	return nil
 }
+
+// NotifySource implements Notifier.
+func (n *DryRunNotifier) NotifySource(_ context.Context, stack string, alert SourceAlert) error {
+	event := n.logger.Info().
+		Str("stack", stack).
+		Str("kind", string(alert.Kind)).
+		Bool("resolved", alert.Resolved).
+		Str("message", alert.Message)
+	if alert.Error != "" {
+		event = event.Str("error", alert.Error)
+	}
+	if alert.Revision != nil {
+		event = event.Str("desired_commit", alert.Revision.Commit)
+	}
+	if alert.ParseError != nil {
+		event = event.Str("file", alert.ParseError.File).
+			Int("line", alert.ParseError.Line).
+			Int("column", alert.ParseError.Column).
+			Str("service", alert.ParseError.Service).
+			Str("field", alert.ParseError.Field)
+	}
+	event.Msg("[DRY-RUN] Would notify source alert")
+	return nil
+}
@@ -18,6 +18,11 @@ func (n *countingNotifier) Notify(context.Context, string, []transition.ServiceT
 	return nil
 }
 
+func (n *countingNotifier) NotifySource(context.Context, string, SourceAlert) error {
+	n.calls++
+	return nil
+}
+
 func TestDryRunNotifierSuppressesDelivery(t *testing.T) {
 	inner := &countingNotifier{}
 	dryRun := NewDryRunNotifier(zerolog.Nop(), inner)
@@ -29,6 +34,9 @@ func TestDryRunNotifierSuppressesDelivery(t *testing.T) {
 	if err := dryRun.Notify(context.Background(), "alpha", transitions); err != nil {
 		t.Fatalf("Notify error: %v", err)
 	}
+	if err := dryRun.NotifySource(context.Background(), "alpha", SourceAlert{Kind: SourceSignatureInvalid}); err != nil {
+		t.Fatalf("NotifySource error: %v", err)
+	}
 	if inner.calls != 0 {
 		t.Fatalf("expected no notifier calls, got %d", inner.calls)
 	}
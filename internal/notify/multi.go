@@ -36,3 +36,17 @@ func (m *MultiNotifier) Notify(ctx context.Context, stack string, transitions []
 	}
 	return firstErr
 }
+
+// NotifySource implements Notifier.
+func (m *MultiNotifier) NotifySource(ctx context.Context, stack string, alert SourceAlert) error {
+	var firstErr error
+	for _, notifier := range m.notifiers {
+		if notifier == nil {
+			continue
+		}
+		if err := notifier.NotifySource(ctx, stack, alert); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -25,3 +25,8 @@ func NewNoop(logger zerolog.Logger, reason string) *NoopNotifier {
 func (n *NoopNotifier) Notify(_ context.Context, _ string, _ []transition.ServiceTransition) error {
 	return nil
 }
+
+// NotifySource implements Notifier.
+func (n *NoopNotifier) NotifySource(_ context.Context, _ string, _ SourceAlert) error {
+	return nil
+}
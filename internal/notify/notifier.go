@@ -7,6 +7,9 @@ import (
 )
 
 // Notifier delivers transition alerts to external systems.
+// NotifySource reports problems with a stack's desired-state source, which are
+// not tied to any single service.
 type Notifier interface {
 	Notify(ctx context.Context, stack string, transitions []transition.ServiceTransition) error
+	NotifySource(ctx context.Context, stack string, alert SourceAlert) error
 }
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/nholik/swarm-sentinel/internal/compose"
 	"github.com/nholik/swarm-sentinel/internal/health"
 	"github.com/nholik/swarm-sentinel/internal/transition"
 	"github.com/rs/zerolog"
@@ -95,6 +96,33 @@ func (n *SlackNotifier) Notify(ctx context.Context, stack string, transitions []
 	return nil
 }
 
+// NotifySource implements Notifier.
+func (n *SlackNotifier) NotifySource(ctx context.Context, stack string, alert SourceAlert) error {
+	stackName := stack
+	if stackName == "" {
+		stackName = "default"
+	}
+	if err := n.poster.waitForRateLimit(ctx, stackName); err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(buildSlackSourceMessage(stackName, alert))
+	if err != nil {
+		return fmt.Errorf("marshal slack payload: %w", err)
+	}
+	if err := n.poster.postWithRetry(ctx, payload); err != nil {
+		return err
+	}
+
+	n.logger.Debug().
+		Str("stack", stackName).
+		Str("kind", string(alert.Kind)).
+		Bool("resolved", alert.Resolved).
+		Msg("slack source alert sent")
+
+	return nil
+}
+
 func (n *SlackNotifier) postOnce(ctx context.Context, payload []byte) error {
 	return n.poster.postOnce(ctx, payload)
 }
@@ -134,6 +162,9 @@ func buildSlackMessage(stack string, transitions []transition.ServiceTransition,
 	if partTotal > 1 {
 		contextElements = append(contextElements, slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("Batch: %d/%d", partIndex, partTotal), false, false))
 	}
+	if revision := transitions[0].DesiredRevision; revision != nil {
+		contextElements = append(contextElements, slack.NewTextBlockObject("mrkdwn", formatRevision(revision), false, false))
+	}
 	context := slack.NewContextBlock("", contextElements...)
 
 	blocks := []slack.Block{header, context}
@@ -148,11 +179,65 @@ func buildSlackMessage(stack string, transitions []transition.ServiceTransition,
 	}
 }
 
+func buildSlackSourceMessage(stack string, alert SourceAlert) slack.WebhookMessage {
+	summary := fmt.Sprintf("Stack %s: %s", stack, alert.Message)
+	header := slack.NewHeaderBlock(slack.NewTextBlockObject("plain_text", summary, false, false))
+
+	state := "FIRING"
+	if alert.Resolved {
+		state = "RESOLVED"
+	}
+	contextElements := []slack.MixedElement{
+		slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("Stack: *%s*", stack), false, false),
+		slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("Source alert: `%s` %s", alert.Kind, state), false, false),
+	}
+	if alert.Revision != nil {
+		contextElements = append(contextElements, slack.NewTextBlockObject("mrkdwn", formatRevision(alert.Revision), false, false))
+	}
+
+	blocks := []slack.Block{header, slack.NewContextBlock("", contextElements...)}
+	if alert.ParseError != nil {
+		blocks = append(blocks, buildParseErrorBlock(alert.ParseError))
+	}
+	if alert.Error != "" {
+		text := slack.NewTextBlockObject("mrkdwn", "*Error:*\n```"+alert.Error+"```", false, false)
+		blocks = append(blocks, slack.NewSectionBlock(text, nil, nil))
+	}
+
+	blockSet := slack.Blocks{BlockSet: blocks}
+	return slack.WebhookMessage{
+		Text:   summary,
+		Blocks: &blockSet,
+	}
+}
+
+func buildParseErrorBlock(perr *compose.ParseError) slack.Block {
+	location := perr.File
+	if location == "" {
+		location = "unknown"
+	} else if perr.Line > 0 {
+		location = fmt.Sprintf("%s:%d", location, perr.Line)
+		if perr.Column > 0 {
+			location = fmt.Sprintf("%s:%d", location, perr.Column)
+		}
+	}
+	fields := []*slack.TextBlockObject{
+		slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Location:*\n`%s`", location), false, false),
+	}
+	if perr.Service != "" {
+		fields = append(fields, slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Service:*\n`%s`", perr.Service), false, false))
+	}
+	if perr.Field != "" {
+		fields = append(fields, slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Field:*\n`%s`", perr.Field), false, false))
+	}
+	return slack.NewSectionBlock(nil, fields, nil)
+}
+
 func buildTransitionBlock(change transition.ServiceTransition) slack.Block {
 	title := fmt.Sprintf("*%s*: `%s` → `%s`", change.Name, statusLabel(change.PreviousStatus), statusLabel(change.CurrentStatus))
 	text := slack.NewTextBlockObject("mrkdwn", title, false, false)
 
-	fields := make([]*slack.TextBlockObject, 0, 4)
+	fields := make([]*slack.TextBlockObject, 0, 5)
 	if len(change.Reasons) > 0 {
 		fields = append(fields, slack.NewTextBlockObject("mrkdwn", "*Reasons:*\n"+strings.Join(change.Reasons, ", "), false, false))
 	}
@@ -162,6 +247,9 @@ func buildTransitionBlock(change transition.ServiceTransition) slack.Block {
 	if change.ImageChange != nil {
 		fields = append(fields, slack.NewTextBlockObject("mrkdwn", formatImageChange(change.ImageChange), false, false))
 	}
+	if change.Job != nil {
+		fields = append(fields, slack.NewTextBlockObject("mrkdwn", formatJob(change.Job), false, false))
+	}
 	if len(change.Drift) > 0 {
 		fields = append(fields, slack.NewTextBlockObject("mrkdwn", formatDrift(change.Drift), false, false))
 	}
@@ -174,6 +262,11 @@ func formatReplicaChange(change *transition.ReplicaChange) string {
 		change.CurrentDesired, change.DesiredDelta, change.CurrentRunning, change.RunningDelta)
 }
 
+func formatJob(job *health.JobHealth) string {
+	return fmt.Sprintf("*Job:*\n%s, %d/%d completed, %d of %d attempts failed",
+		job.State, job.Completed, job.Total, job.Failed, job.Attempts)
+}
+
 func formatImageChange(change *transition.ImageChange) string {
 	desired := change.CurrentDesired
 	if desired == "" {
@@ -189,6 +282,10 @@ func formatImageChange(change *transition.ImageChange) string {
 func formatDrift(drift []health.DriftDetail) string {
 	parts := make([]string, 0, len(drift))
 	for _, detail := range drift {
+		if detail.Desired != "" || detail.Actual != "" {
+			parts = append(parts, fmt.Sprintf("%s %s/%s: %s → %s", detail.Kind, detail.Resource, detail.Name, driftValue(detail.Desired), driftValue(detail.Actual)))
+			continue
+		}
 		if detail.Resource != "" && detail.Name != "" {
 			parts = append(parts, fmt.Sprintf("%s %s/%s", detail.Kind, detail.Resource, detail.Name))
 			continue
@@ -202,6 +299,24 @@ func formatDrift(drift []health.DriftDetail) string {
 	return "*Drift:*\n• " + strings.Join(parts, "\n• ")
 }
 
+func driftValue(value string) string {
+	if value == "" {
+		return "_none_"
+	}
+	return "`" + value + "`"
+}
+
+func formatRevision(revision *compose.Revision) string {
+	commit := revision.Commit
+	if len(commit) > 12 {
+		commit = commit[:12]
+	}
+	if revision.Author == "" {
+		return fmt.Sprintf("Desired state: `%s`", commit)
+	}
+	return fmt.Sprintf("Desired state: `%s` by %s", commit, revision.Author)
+}
+
 func statusLabel(status health.ServiceStatus) string {
 	if status == "" {
 		return "UNKNOWN"
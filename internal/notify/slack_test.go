@@ -12,9 +12,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/nholik/swarm-sentinel/internal/compose"
 	"github.com/nholik/swarm-sentinel/internal/health"
 	"github.com/nholik/swarm-sentinel/internal/transition"
 	"github.com/rs/zerolog"
+	"github.com/slack-go/slack"
 )
 
 func TestBuildSlackMessagesSingle(t *testing.T) {
@@ -232,3 +234,60 @@ func makeTransitions(count int) []transition.ServiceTransition {
 	}
 	return transitions
 }
+
+func TestBuildSlackSourceMessage(t *testing.T) {
+	msg := buildSlackSourceMessage("alpha", SourceAlert{
+		Kind:    SourceSignatureInvalid,
+		Message: "compose signature verification failed",
+		Error:   "signature does not match any trusted key",
+	})
+	if !strings.Contains(msg.Text, "Stack alpha: compose signature verification failed") {
+		t.Fatalf("unexpected summary: %q", msg.Text)
+	}
+	if msg.Blocks == nil || len(msg.Blocks.BlockSet) != 3 {
+		t.Fatalf("expected header, context and error blocks")
+	}
+
+	resolved := buildSlackSourceMessage("alpha", SourceAlert{Kind: SourceSignatureInvalid, Resolved: true, Message: "compose signature verified"})
+	if len(resolved.Blocks.BlockSet) != 2 {
+		t.Fatalf("expected no error block for resolved alert, got %d blocks", len(resolved.Blocks.BlockSet))
+	}
+}
+
+func TestBuildSlackSourceMessage_ParseError(t *testing.T) {
+	msg := buildSlackSourceMessage("alpha", SourceAlert{
+		Kind:       SourceParseError,
+		Message:    "compose could not be parsed",
+		Error:      `compose.yml:4:5: service "web" field "ports": must be a array`,
+		ParseError: &compose.ParseError{File: "compose.yml", Line: 4, Column: 5, Service: "web", Field: "ports", Message: "must be a array"},
+	})
+	if msg.Blocks == nil || len(msg.Blocks.BlockSet) != 4 {
+		t.Fatalf("expected header, context, location and error blocks")
+	}
+	section, ok := msg.Blocks.BlockSet[2].(*slack.SectionBlock)
+	if !ok || len(section.Fields) != 3 {
+		t.Fatalf("expected location, service and field, got %+v", msg.Blocks.BlockSet[2])
+	}
+	if !strings.Contains(section.Fields[0].Text, "compose.yml:4:5") || !strings.Contains(section.Fields[2].Text, "ports") {
+		t.Fatalf("unexpected location fields: %q, %q", section.Fields[0].Text, section.Fields[2].Text)
+	}
+}
+
+func TestFormatDrift(t *testing.T) {
+	got := formatDrift([]health.DriftDetail{
+		{Kind: health.DriftMissing, Resource: "config", Name: "app_config"},
+		{Kind: health.DriftChanged, Resource: "port", Name: "80/tcp", Desired: "8080:80/tcp", Actual: "8081:80/tcp"},
+	})
+	want := "*Drift:*\n• MISSING config/app_config\n• CHANGED port/80/tcp: `8080:80/tcp` → `8081:80/tcp`"
+	if got != want {
+		t.Fatalf("unexpected drift text:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestFormatJob(t *testing.T) {
+	got := formatJob(&health.JobHealth{State: health.JobFailed, Completed: 1, Total: 3, Failed: 4, Attempts: 5})
+	want := "*Job:*\nfailed, 1/3 completed, 4 of 5 attempts failed"
+	if got != want {
+		t.Fatalf("unexpected job text:\n got %q\nwant %q", got, want)
+	}
+}
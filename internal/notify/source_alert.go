@@ -0,0 +1,33 @@
+package notify
+
+import "github.com/nholik/swarm-sentinel/internal/compose"
+
+// SourceAlertKind identifies the kind of desired-state source problem.
+type SourceAlertKind string
+
+const (
+	// SourceSignatureInvalid reports a compose file that failed detached
+	// signature verification and was not adopted as the desired state.
+	SourceSignatureInvalid SourceAlertKind = "signature_invalid"
+	// SourceUnreachable reports a compose source that failed to fetch or
+	// parse for several consecutive cycles.
+	SourceUnreachable SourceAlertKind = "source_unreachable"
+	// SourceStale reports a desired state whose source has not been
+	// modified for longer than the configured maximum age.
+	SourceStale SourceAlertKind = "source_stale"
+	// SourceParseError reports compose content that could not be parsed.
+	// ParseError locates the failure.
+	SourceParseError SourceAlertKind = "parse_error"
+)
+
+// SourceAlert describes a problem with a stack's desired-state source, or its
+// resolution when Resolved is set.
+type SourceAlert struct {
+	Kind     SourceAlertKind
+	Resolved bool
+	Message  string
+	Error    string
+	Revision *compose.Revision
+	// ParseError is set for SourceParseError alerts that are firing.
+	ParseError *compose.ParseError
+}
@@ -12,20 +12,29 @@ import (
 	"github.com/rs/zerolog"
 )
 
-const defaultWebhookTemplate = `{"stack":"{{ .Stack }}","transitions":{{ toJson .Transitions }}}`
+const (
+	defaultWebhookTemplate       = `{"stack":"{{ .Stack }}","transitions":{{ toJson .Transitions }}}`
+	defaultWebhookSourceTemplate = `{"stack":"{{ .Stack }}","alert":{{ toJson .Alert }}}`
+)
 
 // WebhookPayload is the template context for webhook notifications.
+// Alert is set (and Transitions empty) for desired-state source alerts.
 type WebhookPayload struct {
 	Stack       string
 	Transitions []transition.ServiceTransition
+	Alert       *SourceAlert
 	GeneratedAt time.Time
 }
 
 // WebhookNotifier sends transition notifications to a generic webhook.
+// A custom template only shapes transition payloads; source alerts always
+// use the default JSON payload, since templates written for transitions
+// have nothing to render for them.
 type WebhookNotifier struct {
-	logger   zerolog.Logger
-	template *template.Template
-	poster   *httpPoster
+	logger         zerolog.Logger
+	template       *template.Template
+	sourceTemplate *template.Template
+	poster         *httpPoster
 }
 
 // NewWebhookNotifier creates a webhook notifier with the provided template.
@@ -37,6 +46,24 @@ func NewWebhookNotifier(logger zerolog.Logger, webhookURL string, tmpl string) (
 		tmpl = defaultWebhookTemplate
 	}
 
+	parsed, err := parseWebhookTemplate(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	parsedSource, err := parseWebhookTemplate(defaultWebhookSourceTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WebhookNotifier{
+		logger:         logger,
+		template:       parsed,
+		sourceTemplate: parsedSource,
+		poster:         newHTTPPoster(logger, "webhook", webhookURL, "application/json", defaultTiming),
+	}, nil
+}
+
+func parseWebhookTemplate(tmpl string) (*template.Template, error) {
 	parsed, err := template.New("webhook").Funcs(template.FuncMap{
 		"toJson": func(v any) (string, error) {
 			encoded, err := json.Marshal(v)
@@ -49,12 +76,7 @@ func NewWebhookNotifier(logger zerolog.Logger, webhookURL string, tmpl string) (
 	if err != nil {
 		return nil, fmt.Errorf("parse webhook template: %w", err)
 	}
-
-	return &WebhookNotifier{
-		logger:   logger,
-		template: parsed,
-		poster:   newHTTPPoster(logger, "webhook", webhookURL, "application/json", defaultTiming),
-	}, nil
+	return parsed, nil
 }
 
 // Notify implements Notifier.
@@ -94,3 +116,42 @@ func (n *WebhookNotifier) Notify(ctx context.Context, stack string, transitions
 
 	return nil
 }
+
+// NotifySource implements Notifier.
+func (n *WebhookNotifier) NotifySource(ctx context.Context, stack string, alert SourceAlert) error {
+	if n == nil {
+		return nil
+	}
+
+	stackName := stack
+	if stackName == "" {
+		stackName = "default"
+	}
+
+	if err := n.poster.waitForRateLimit(ctx, stackName); err != nil {
+		return err
+	}
+
+	payload := WebhookPayload{
+		Stack:       stackName,
+		Alert:       &alert,
+		GeneratedAt: time.Now().UTC(),
+	}
+
+	var buf bytes.Buffer
+	if err := n.sourceTemplate.Execute(&buf, payload); err != nil {
+		return fmt.Errorf("render webhook template: %w", err)
+	}
+
+	if err := n.poster.postWithRetry(ctx, buf.Bytes()); err != nil {
+		return err
+	}
+
+	n.logger.Debug().
+		Str("stack", stackName).
+		Str("kind", string(alert.Kind)).
+		Bool("resolved", alert.Resolved).
+		Msg("webhook source alert sent")
+
+	return nil
+}
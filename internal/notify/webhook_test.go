@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/nholik/swarm-sentinel/internal/compose"
 	"github.com/nholik/swarm-sentinel/internal/health"
 	"github.com/nholik/swarm-sentinel/internal/transition"
 	"github.com/rs/zerolog"
@@ -82,3 +83,61 @@ func TestWebhookNotifierInvalidTemplate(t *testing.T) {
 		t.Fatalf("expected template error")
 	}
 }
+
+func TestWebhookNotifierSourceAlertDefaultTemplate(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier, err := NewWebhookNotifier(zerolog.Nop(), server.URL, "")
+	if err != nil {
+		t.Fatalf("NewWebhookNotifier error: %v", err)
+	}
+
+	alert := SourceAlert{Kind: SourceSignatureInvalid, Message: "compose signature verification failed", Error: "bad signature"}
+	if err := notifier.NotifySource(context.Background(), "alpha", alert); err != nil {
+		t.Fatalf("NotifySource error: %v", err)
+	}
+
+	if !strings.Contains(body, `"stack":"alpha"`) {
+		t.Fatalf("expected stack in payload, got %s", body)
+	}
+	if !strings.Contains(body, `"Kind":"signature_invalid"`) {
+		t.Fatalf("expected alert kind in payload, got %s", body)
+	}
+}
+
+func TestWebhookNotifierSourceAlertIgnoresCustomTemplate(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier, err := NewWebhookNotifier(zerolog.Nop(), server.URL, `{"services":[{{range .Transitions}}"{{.Name}}"{{end}}]}`)
+	if err != nil {
+		t.Fatalf("NewWebhookNotifier error: %v", err)
+	}
+
+	alert := SourceAlert{
+		Kind:       SourceParseError,
+		Message:    "compose could not be parsed",
+		ParseError: &compose.ParseError{File: "compose.yml", Line: 4, Message: "must be a array"},
+	}
+	if err := notifier.NotifySource(context.Background(), "alpha", alert); err != nil {
+		t.Fatalf("NotifySource error: %v", err)
+	}
+
+	if !strings.Contains(body, `"stack":"alpha"`) || !strings.Contains(body, `"Kind":"parse_error"`) {
+		t.Fatalf("expected default source alert payload, got %s", body)
+	}
+	if !strings.Contains(body, `"ParseError":{"File":"compose.yml","Line":4,`) {
+		t.Fatalf("expected parse error fields named like the rest of the payload, got %s", body)
+	}
+}
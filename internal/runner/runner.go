@@ -3,6 +3,7 @@ package runner
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"sync"
 	"time"
@@ -43,19 +44,53 @@ type Runner struct {
 	tickerFactory            func(time.Duration) Ticker
 	runOnce                  func(context.Context) error
 	composeFetcher           compose.Fetcher
+	composeVerifier          compose.Verifier
+	sources                  []*composeSource
+	envSource                *composeSource
 	swarmClient              swarm.Client
 	stackName                string
-	composeETag              string
 	composeHash              string
+	composeRawHash           string
+	desiredRevision          *compose.Revision
 	lastDesiredState         *compose.DesiredState
 	lastActualState          *swarm.ActualState
 	stateStore               state.Store
 	stateMu                  *sync.Mutex
+	desiredCache             state.DesiredCache
+	desiredCacheLoaded       bool
+	desiredCachedAt          time.Time
+	parseErr                 error
+	sourceFailureThreshold   int
+	sourceMaxAge             time.Duration
+	sourceFailures           int
+	sourceUnreachable        bool
+	sourceStale              bool
 	notifier                 notify.Notifier
 	alertStabilizationCycles int
 	cycleTracker             *healthcheck.Tracker
 	metrics                  *metrics.Metrics
 	stacksEvaluated          int
+	healthOptions            []health.EvaluateOption
+}
+
+// ComposeSource is one compose file of a stack's desired state. Sources are
+// fetched independently and merged in order, later files overriding earlier ones.
+// Verifier is optional.
+type ComposeSource struct {
+	Name     string
+	Fetcher  compose.Fetcher
+	Verifier compose.Verifier
+}
+
+// composeSource tracks the fetch state of a single compose file.
+type composeSource struct {
+	ComposeSource
+	etag                string
+	body                []byte
+	fetched             bool
+	revision            *compose.Revision
+	lastModified        time.Time
+	rejectedFingerprint string
 }
 
 // Option customizes runner behavior.
@@ -82,6 +117,39 @@ func WithComposeFetcher(fetcher compose.Fetcher) Option {
 	}
 }
 
+// WithComposeVerifier requires each new compose body from the WithComposeFetcher
+// source to pass verification before it is parsed. Rejected bodies never
+// replace the last trusted desired state.
+func WithComposeVerifier(verifier compose.Verifier) Option {
+	return func(r *Runner) {
+		r.composeVerifier = verifier
+	}
+}
+
+// WithComposeEnv enables strict variable interpolation, resolving compose
+// variables from the env file served by fetcher. Edits to the env file are
+// treated like compose changes.
+func WithComposeEnv(fetcher compose.Fetcher) Option {
+	return func(r *Runner) {
+		if fetcher == nil {
+			r.envSource = nil
+			return
+		}
+		r.envSource = &composeSource{ComposeSource: ComposeSource{Name: "env file", Fetcher: fetcher}}
+	}
+}
+
+// WithComposeSources sets several compose files that are merged in order into
+// the desired state. It takes precedence over WithComposeFetcher.
+func WithComposeSources(sources ...ComposeSource) Option {
+	return func(r *Runner) {
+		r.sources = r.sources[:0]
+		for _, source := range sources {
+			r.sources = append(r.sources, &composeSource{ComposeSource: source})
+		}
+	}
+}
+
 // WithSwarmClient sets the Swarm client used by the default RunOnce.
 func WithSwarmClient(client swarm.Client) Option {
 	return func(r *Runner) {
@@ -104,6 +172,33 @@ func WithStateStore(store state.Store, lock *sync.Mutex) Option {
 	}
 }
 
+// WithDesiredStateCache persists the last successfully parsed compose content
+// so health can be evaluated against it when the compose source is
+// unreachable after a restart.
+func WithDesiredStateCache(cache state.DesiredCache) Option {
+	return func(r *Runner) {
+		r.desiredCache = cache
+	}
+}
+
+// WithSourceAlerts enables source-level alerts: failureThreshold consecutive
+// cycles that fail to fetch or parse the compose source raise an unreachable
+// alert, and a desired state last modified more than maxAge ago raises a
+// stale alert. Zero disables either check.
+func WithSourceAlerts(failureThreshold int, maxAge time.Duration) Option {
+	return func(r *Runner) {
+		r.sourceFailureThreshold = failureThreshold
+		r.sourceMaxAge = maxAge
+	}
+}
+
+// WithHealthOptions customizes how desired and actual state are compared.
+func WithHealthOptions(opts ...health.EvaluateOption) Option {
+	return func(r *Runner) {
+		r.healthOptions = opts
+	}
+}
+
 // WithNotifier enables transition notifications.
 func WithNotifier(notifier notify.Notifier) Option {
 	return func(r *Runner) {
@@ -158,6 +253,13 @@ func New(logger zerolog.Logger, pollInterval time.Duration, opts ...Option) *Run
 	if r.stateStore != nil && r.stateMu == nil {
 		r.stateMu = &sync.Mutex{}
 	}
+	if len(r.sources) == 0 && r.composeFetcher != nil {
+		r.sources = []*composeSource{{ComposeSource: ComposeSource{
+			Name:     "compose.yml",
+			Fetcher:  r.composeFetcher,
+			Verifier: r.composeVerifier,
+		}}}
+	}
 
 	return r
 }
@@ -225,45 +327,32 @@ func (r *Runner) defaultRunOnce(ctx context.Context) error {
 		return err
 	}
 
-	if r.composeFetcher != nil {
-		result, err := r.composeFetcher.Fetch(ctx, r.composeETag)
-		if err != nil {
-			return wrapRuntime("compose fetch", err)
-		}
-
-		if result.ETag != "" {
-			r.composeETag = result.ETag
+	if len(r.sources) > 0 {
+		if !r.desiredCacheLoaded {
+			r.desiredCacheLoaded = true
+			r.loadDesiredCache(ctx)
 		}
-
-		if result.NotModified {
-			r.logger.Debug().Msg("compose unchanged")
-		} else {
-			fingerprint, err := compose.Fingerprint(result.Body)
-			if err != nil {
-				return wrapRuntime("compose fingerprint", err)
-			}
-			if fingerprint == r.composeHash {
-				r.logger.Debug().Msg("compose fingerprint unchanged")
-			} else {
-				r.composeHash = fingerprint
-
-				r.logger.Info().
-					Int("bytes", len(result.Body)).
-					Str("etag", result.ETag).
-					Str("last_modified", result.LastModified).
-					Str("fingerprint", fingerprint).
-					Msg("compose fetched")
-
-				desiredState, err := compose.ParseDesiredState(ctx, result.Body)
-				if err != nil {
-					return wrapRuntime("compose parse", err)
-				}
-				r.lastDesiredState = &desiredState
-
-				r.logger.Info().
-					Int("services", len(desiredState.Services)).
-					Msg("parsed desired state")
+		err := r.refreshDesiredState(ctx)
+		r.trackSourceHealth(ctx, err)
+		if err != nil {
+			switch {
+			case r.lastDesiredState != nil && rejectedCompose(err):
+				// The rejection was alerted; drift detection carries on
+				// against the content that last verified and parsed.
+				r.withRevision(r.logger.Warn()).Err(err).
+					Str("stack_name", r.stackKey()).
+					Msg("compose rejected, evaluating against last trusted desired state")
+			case !r.desiredCachedAt.IsZero():
+				r.logger.Warn().Err(err).
+					Str("stack_name", r.stackKey()).
+					Time("cached_at", r.desiredCachedAt).
+					Msg("compose source unavailable, evaluating against cached desired state")
+			default:
+				return err
 			}
+		} else if !r.desiredCachedAt.IsZero() {
+			r.logger.Info().Str("stack_name", r.stackKey()).Msg("compose source available, no longer using cached desired state")
+			r.setDesiredStateCached(time.Time{})
 		}
 	}
 
@@ -303,6 +392,9 @@ func (r *Runner) defaultRunOnce(ctx context.Context) error {
 	if r.stackName != "" {
 		event = event.Str("stack_name", r.stackName)
 	}
+	if !r.desiredCachedAt.IsZero() {
+		event = event.Bool("cached_desired_state", true)
+	}
 	event.Msg("collected actual state")
 
 	if r.stateStore != nil && r.lastDesiredState != nil {
@@ -314,9 +406,273 @@ func (r *Runner) defaultRunOnce(ctx context.Context) error {
 	return nil
 }
 
+// rejectedCompose reports whether err rejected fetched compose content, as
+// opposed to failing to fetch it.
+func rejectedCompose(err error) bool {
+	var runtimeErr *RuntimeError
+	return errors.As(err, &runtimeErr) && (runtimeErr.Op == "compose verify" || runtimeErr.Op == "compose parse")
+}
+
+// refreshDesiredState fetches every compose source and re-parses the merged
+// desired state when the combined content changed.
+func (r *Runner) refreshDesiredState(ctx context.Context) error {
+	for _, src := range r.sources {
+		if err := r.fetchSource(ctx, src); err != nil {
+			return err
+		}
+	}
+	if r.envSource != nil {
+		if err := r.fetchSource(ctx, r.envSource); err != nil {
+			return err
+		}
+	}
+
+	bodies := make([][]byte, 0, len(r.sources))
+	files := make([]compose.File, 0, len(r.sources))
+	size := 0
+	for _, src := range r.sources {
+		bodies = append(bodies, src.body)
+		files = append(files, compose.File{Name: src.Name, Body: src.body})
+		size += len(src.body)
+	}
+
+	rawFingerprint, err := compose.CombinedFingerprint(bodies)
+	if err != nil {
+		return wrapRuntime("compose fingerprint", err)
+	}
+	if r.envSource != nil {
+		rawFingerprint = compose.EnvFingerprint(rawFingerprint, r.envSource.body)
+	}
+	if rawFingerprint == r.composeRawHash {
+		// Content that failed to parse is not parsed (or alerted) again,
+		// but keeps failing every cycle until it changes.
+		r.logger.Debug().Msg("compose unchanged")
+		return r.parseErr
+	}
+	r.composeRawHash = rawFingerprint
+
+	event := r.logger.Info().
+		Int("bytes", size).
+		Int("files", len(files)).
+		Str("raw_fingerprint", rawFingerprint)
+	if len(r.sources) == 1 {
+		event = event.Str("etag", r.sources[0].etag)
+	}
+	if revision := r.fetchedRevision(); revision != nil {
+		event = event.Str("commit", revision.Commit)
+	}
+	event.Msg("compose fetched")
+
+	var parseOpts []compose.ParseOption
+	if r.envSource != nil {
+		env, err := compose.ParseEnvFile(r.envSource.body)
+		if err != nil {
+			perr := &compose.ParseError{File: r.envSource.Name, Message: err.Error()}
+			r.alertParseError(ctx, perr)
+			r.parseErr = wrapRuntime("compose parse", perr)
+			return r.parseErr
+		}
+		parseOpts = append(parseOpts, compose.WithEnvironment(env))
+	}
+	if r.stackName != "" {
+		parseOpts = append(parseOpts, compose.WithStackName(r.stackName))
+	}
+
+	desiredState, err := compose.ParseDesiredStateFiles(ctx, files, parseOpts...)
+	if err != nil {
+		var perr *compose.ParseError
+		if !errors.As(err, &perr) {
+			perr = &compose.ParseError{Message: err.Error()}
+		}
+		r.alertParseError(ctx, perr)
+		r.parseErr = wrapRuntime("compose parse", err)
+		return r.parseErr
+	}
+	// Only content that parsed defines the desired state, so the revision
+	// is promoted here rather than when it is fetched.
+	if revision := r.fetchedRevision(); revision != nil {
+		r.desiredRevision = revision
+	}
+	if r.parseErr != nil {
+		r.resolveParseError(ctx)
+	}
+	r.parseErr = nil
+	r.lastDesiredState = &desiredState
+	r.saveDesiredCache(ctx, rawFingerprint, desiredState.Fingerprint)
+
+	if desiredState.Fingerprint == r.composeHash {
+		r.logger.Info().
+			Str("fingerprint", desiredState.Fingerprint).
+			Msg("compose reformatted, semantic fingerprint unchanged")
+		return nil
+	}
+	r.composeHash = desiredState.Fingerprint
+	r.logger.Info().
+		Int("services", len(desiredState.Services)).
+		Str("fingerprint", desiredState.Fingerprint).
+		Msg("parsed desired state")
+	return nil
+}
+
+// fetchedRevision returns the revision of the latest fetched compose content,
+// which becomes the desired revision once that content parses.
+func (r *Runner) fetchedRevision() *compose.Revision {
+	var revision *compose.Revision
+	for _, src := range r.sources {
+		if src.revision != nil {
+			revision = src.revision
+		}
+	}
+	return revision
+}
+
+// fetchSource refreshes a single compose source using its own ETag.
+func (r *Runner) fetchSource(ctx context.Context, src *composeSource) error {
+	result, err := src.Fetcher.Fetch(ctx, src.etag)
+	if err != nil {
+		return wrapRuntime("compose fetch", err)
+	}
+
+	// Verify before recording the ETag so a rejected body is re-fetched
+	// and re-checked on the next cycle.
+	if !result.NotModified && src.Verifier != nil {
+		if err := src.Verifier.Verify(ctx, result.Body); err != nil {
+			r.alertVerificationFailure(ctx, src, result, err)
+			return wrapRuntime("compose verify", err)
+		}
+		r.resolveVerificationAlert(ctx, src, result)
+	}
+
+	if result.ETag != "" {
+		src.etag = result.ETag
+	}
+	if result.Revision != nil {
+		src.revision = result.Revision
+	}
+	if modified, ok := compose.ParseLastModified(result.LastModified); ok {
+		src.lastModified = modified
+	}
+	if result.NotModified {
+		if !src.fetched {
+			return wrapRuntime("compose fetch", errors.New("source reported not modified before any content was fetched"))
+		}
+		return nil
+	}
+	src.body = result.Body
+	src.fetched = true
+	return nil
+}
+
+// loadDesiredCache restores the last known good desired state at startup. When
+// the cached files match the configured sources their ETags and bodies are
+// reused, so an unchanged source answers with a cheap conditional response.
+func (r *Runner) loadDesiredCache(ctx context.Context) {
+	if r.desiredCache == nil {
+		return
+	}
+	cached, err := r.desiredCache.LoadDesired(ctx, r.stackKey())
+	if err != nil {
+		r.logger.Warn().Err(err).Str("stack_name", r.stackKey()).Msg("failed to load cached desired state")
+		return
+	}
+	if cached == nil {
+		return
+	}
+
+	files := make([]compose.File, 0, len(cached.Files))
+	for _, file := range cached.Files {
+		files = append(files, compose.File{Name: file.Name, Body: file.Body})
+	}
+	var parseOpts []compose.ParseOption
+	if r.envSource != nil {
+		env, err := compose.ParseEnvFile(cached.Env)
+		if err != nil {
+			r.logger.Warn().Err(err).Str("stack_name", r.stackKey()).Msg("ignoring cached desired state")
+			return
+		}
+		parseOpts = append(parseOpts, compose.WithEnvironment(env))
+	}
+	if r.stackName != "" {
+		parseOpts = append(parseOpts, compose.WithStackName(r.stackName))
+	}
+	desiredState, err := compose.ParseDesiredStateFiles(ctx, files, parseOpts...)
+	if err != nil {
+		r.logger.Warn().Err(err).Str("stack_name", r.stackKey()).Msg("ignoring cached desired state")
+		return
+	}
+
+	r.lastDesiredState = &desiredState
+	r.composeHash = desiredState.Fingerprint
+	r.desiredRevision = cached.Revision
+	if r.sourcesMatch(cached.Files) {
+		for i, src := range r.sources {
+			src.etag = cached.Files[i].ETag
+			src.body = cached.Files[i].Body
+			src.fetched = true
+		}
+		if r.envSource != nil {
+			r.envSource.body = cached.Env
+			r.envSource.fetched = true
+		}
+		r.composeRawHash = cached.RawFingerprint
+	}
+	r.setDesiredStateCached(cached.CachedAt)
+
+	r.withRevision(r.logger.Warn().
+		Str("stack_name", r.stackKey()).
+		Int("services", len(desiredState.Services)).
+		Str("fingerprint", desiredState.Fingerprint).
+		Time("cached_at", cached.CachedAt)).
+		Msg("loaded cached desired state")
+}
+
+// sourcesMatch reports whether cached files line up with the configured sources.
+func (r *Runner) sourcesMatch(files []state.CachedComposeFile) bool {
+	if len(files) != len(r.sources) {
+		return false
+	}
+	for i, src := range r.sources {
+		if files[i].Name != src.Name {
+			return false
+		}
+	}
+	return true
+}
+
+// saveDesiredCache records the sources that produced the current desired state.
+func (r *Runner) saveDesiredCache(ctx context.Context, rawFingerprint, fingerprint string) {
+	if r.desiredCache == nil {
+		return
+	}
+	cached := state.CachedDesiredState{
+		Files:          make([]state.CachedComposeFile, 0, len(r.sources)),
+		Fingerprint:    fingerprint,
+		RawFingerprint: rawFingerprint,
+		Revision:       r.desiredRevision,
+		CachedAt:       time.Now().UTC(),
+	}
+	for _, src := range r.sources {
+		cached.Files = append(cached.Files, state.CachedComposeFile{Name: src.Name, ETag: src.etag, Body: src.body})
+	}
+	if r.envSource != nil {
+		cached.Env = r.envSource.body
+	}
+	if err := r.desiredCache.SaveDesired(ctx, r.stackKey(), cached); err != nil {
+		r.logger.Warn().Err(err).Str("stack_name", r.stackKey()).Msg("failed to cache desired state")
+	}
+}
+
+// setDesiredStateCached flags cycles evaluated against a cached desired state
+// in metrics and the health endpoint. A zero cachedAt clears the flag.
+func (r *Runner) setDesiredStateCached(cachedAt time.Time) {
+	r.desiredCachedAt = cachedAt
+	r.cycleTracker.SetDesiredStateCached(r.stackKey(), cachedAt)
+	r.metrics.SetDesiredStateCached(r.stackKey(), !cachedAt.IsZero())
+}
+
 func (r *Runner) evaluateAndPersist(ctx context.Context) error {
 	stackScoped := r.stackName != ""
-	stackHealth := health.EvaluateStackHealth(*r.lastDesiredState, r.lastActualState, stackScoped)
+	stackHealth := health.EvaluateStackHealth(*r.lastDesiredState, r.lastActualState, stackScoped, r.healthOptions...)
 
 	if r.lastActualState != nil {
 		for _, service := range r.lastActualState.Services {
@@ -352,9 +708,10 @@ func (r *Runner) evaluateAndPersist(ctx context.Context) error {
 			loaded.Stacks = map[string]state.StackSnapshot{}
 		}
 		loaded.Stacks[stackKey] = state.StackSnapshot{
-			DesiredFingerprint: r.composeHash,
-			Services:           updatedServices,
-			EvaluatedAt:        now,
+			DesiredFingerprint:    r.composeHash,
+			DesiredRawFingerprint: r.composeRawHash,
+			Services:              updatedServices,
+			EvaluatedAt:           now,
 		}
 
 		if err := r.stateStore.Save(ctx, loaded); err != nil {
@@ -368,6 +725,10 @@ func (r *Runner) evaluateAndPersist(ctx context.Context) error {
 
 	r.logCycleSummary(stackHealth, transitions)
 	r.recordMetrics(stackHealth, transitions)
+	for i := range transitions {
+		transitions[i].DesiredRevision = r.desiredRevision
+	}
+
 	for _, change := range transitions {
 		var event *zerolog.Event
 		switch change.CurrentStatus {
@@ -391,6 +752,13 @@ func (r *Runner) evaluateAndPersist(ctx context.Context) error {
 				Int("desired_delta", change.ReplicaChange.DesiredDelta).
 				Int("running_delta", change.ReplicaChange.RunningDelta)
 		}
+		if change.Job != nil {
+			event = event.Str("job_state", string(change.Job.State)).
+				Int("job_completed", change.Job.Completed).
+				Int("job_total", change.Job.Total).
+				Int("job_failed", change.Job.Failed).
+				Int("job_attempts", change.Job.Attempts)
+		}
 		if change.ImageChange != nil {
 			event = event.Str("desired_image", change.ImageChange.CurrentDesired).
 				Str("actual_image", change.ImageChange.CurrentActual)
@@ -398,7 +766,7 @@ func (r *Runner) evaluateAndPersist(ctx context.Context) error {
 		if len(change.Drift) > 0 {
 			event = event.Interface("drift", change.Drift)
 		}
-		event = event.Str("stack_name", r.stackKey())
+		event = r.withRevision(event.Str("stack_name", r.stackKey()))
 		event.Msg("service transition detected")
 	}
 
@@ -516,15 +884,216 @@ func (r *Runner) logCycleSummary(stackHealth health.StackHealth, transitions []t
 		}
 	}
 
-	r.logger.Info().
+	event := r.logger.Info().
 		Str("stack_name", r.stackKey()).
 		Str("fingerprint", r.composeHash).
+		Str("raw_fingerprint", r.composeRawHash).
 		Int("services_evaluated", len(stackHealth.Services)).
 		Int("services_ok", okCount).
 		Int("services_degraded", degradedCount).
 		Int("services_failed", failedCount).
-		Int("transitions", len(transitions)).
-		Msg("health evaluation summary")
+		Int("transitions", len(transitions))
+	r.withRevision(event).Msg("health evaluation summary")
+}
+
+// alertVerificationFailure notifies once per rejected compose body. Transient
+// errors fetching the signature are not alerted.
+func (r *Runner) alertVerificationFailure(ctx context.Context, src *composeSource, result compose.FetchResult, err error) {
+	var sigErr *compose.SignatureError
+	if !errors.As(err, &sigErr) {
+		return
+	}
+	fingerprint, fpErr := compose.Fingerprint(result.Body)
+	if fpErr != nil {
+		fingerprint = result.ETag
+	}
+
+	event := r.logger.Error().Err(err).
+		Str("stack_name", r.stackKey()).
+		Str("source", src.Name).
+		Str("fingerprint", fingerprint)
+	if result.Revision != nil {
+		event = event.Str("rejected_commit", result.Revision.Commit)
+	}
+	event.Msg("compose rejected, keeping last trusted desired state")
+
+	if fingerprint == src.rejectedFingerprint {
+		return
+	}
+	src.rejectedFingerprint = fingerprint
+	r.notifySource(ctx, notify.SourceAlert{
+		Kind:     notify.SourceSignatureInvalid,
+		Message:  "compose signature verification failed",
+		Error:    err.Error(),
+		Revision: result.Revision,
+	})
+}
+
+// resolveVerificationAlert sends a recovery notice after a previously
+// rejected compose is followed by a verified one.
+func (r *Runner) resolveVerificationAlert(ctx context.Context, src *composeSource, result compose.FetchResult) {
+	if src.rejectedFingerprint == "" {
+		return
+	}
+	src.rejectedFingerprint = ""
+	r.logger.Info().Str("stack_name", r.stackKey()).Str("source", src.Name).Msg("compose signature verified again")
+	r.notifySource(ctx, notify.SourceAlert{
+		Kind:     notify.SourceSignatureInvalid,
+		Resolved: true,
+		Message:  "compose signature verified",
+		Revision: result.Revision,
+	})
+}
+
+// alertParseError reports compose content that failed to parse. It is only
+// called when the raw content changed, so each broken revision alerts once.
+// The alert carries the revision of the desired state still in use; the log
+// also names the commit that failed.
+func (r *Runner) alertParseError(ctx context.Context, perr *compose.ParseError) {
+	event := r.withRevision(r.logger.Error())
+	if revision := r.fetchedRevision(); revision != nil {
+		event = event.Str("commit", revision.Commit)
+	}
+	event.
+		Str("stack_name", r.stackKey()).
+		Str("file", perr.File).
+		Int("line", perr.Line).
+		Int("column", perr.Column).
+		Str("service", perr.Service).
+		Str("field", perr.Field).
+		Str("error", perr.Message).
+		Msg("compose parse failed")
+	r.cycleTracker.SetParseError(r.stackKey(), perr)
+	r.notifySource(ctx, notify.SourceAlert{
+		Kind:       notify.SourceParseError,
+		Message:    "compose could not be parsed",
+		Error:      perr.Error(),
+		Revision:   r.desiredRevision,
+		ParseError: perr,
+	})
+}
+
+func (r *Runner) resolveParseError(ctx context.Context) {
+	r.logger.Info().Str("stack_name", r.stackKey()).Msg("compose parsed after previous failure")
+	r.cycleTracker.SetParseError(r.stackKey(), nil)
+	r.notifySource(ctx, notify.SourceAlert{
+		Kind:     notify.SourceParseError,
+		Resolved: true,
+		Message:  "compose parsed successfully",
+		Revision: r.desiredRevision,
+	})
+}
+
+// trackSourceHealth counts consecutive cycles in which the compose source
+// could not be fetched or its latest content did not parse, and checks the
+// age of the desired state. Verification failures have their own alert.
+func (r *Runner) trackSourceHealth(ctx context.Context, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+	var failure error
+	var runtimeErr *RuntimeError
+	if errors.As(err, &runtimeErr) && (runtimeErr.Op == "compose fetch" || runtimeErr.Op == "compose parse") {
+		failure = err
+	} else if err == nil {
+		failure = r.parseErr
+	}
+
+	switch {
+	case failure != nil:
+		r.sourceFailures++
+		if r.sourceFailureThreshold > 0 && r.sourceFailures >= r.sourceFailureThreshold && !r.sourceUnreachable {
+			r.sourceUnreachable = true
+			r.logger.Error().Err(failure).
+				Str("stack_name", r.stackKey()).
+				Int("consecutive_failures", r.sourceFailures).
+				Msg("compose source unavailable")
+			r.notifySource(ctx, notify.SourceAlert{
+				Kind:     notify.SourceUnreachable,
+				Message:  fmt.Sprintf("compose source failing for %d consecutive cycles", r.sourceFailures),
+				Error:    failure.Error(),
+				Revision: r.desiredRevision,
+			})
+		}
+	case err == nil:
+		if r.sourceUnreachable {
+			r.logger.Info().Str("stack_name", r.stackKey()).Msg("compose source recovered")
+			r.notifySource(ctx, notify.SourceAlert{
+				Kind:     notify.SourceUnreachable,
+				Resolved: true,
+				Message:  "compose source recovered",
+				Revision: r.desiredRevision,
+			})
+		}
+		r.sourceFailures = 0
+		r.sourceUnreachable = false
+	}
+
+	r.checkSourceAge(ctx, time.Now())
+}
+
+// checkSourceAge alerts once the newest compose source is older than the
+// configured maximum age, and resolves when a newer version is fetched.
+// Sources that do not report a modification time are ignored.
+func (r *Runner) checkSourceAge(ctx context.Context, now time.Time) {
+	if r.sourceMaxAge <= 0 {
+		return
+	}
+	var newest time.Time
+	for _, src := range r.sources {
+		if src.lastModified.After(newest) {
+			newest = src.lastModified
+		}
+	}
+	if newest.IsZero() {
+		return
+	}
+
+	age := now.Sub(newest)
+	stale := age > r.sourceMaxAge
+	switch {
+	case stale && !r.sourceStale:
+		r.logger.Warn().
+			Str("stack_name", r.stackKey()).
+			Time("last_modified", newest).
+			Dur("age", age).
+			Msg("desired state is stale")
+		r.notifySource(ctx, notify.SourceAlert{
+			Kind:     notify.SourceStale,
+			Message:  fmt.Sprintf("desired state not updated for %s (limit %s)", age.Truncate(time.Minute), r.sourceMaxAge),
+			Revision: r.desiredRevision,
+		})
+	case !stale && r.sourceStale:
+		r.logger.Info().Str("stack_name", r.stackKey()).Time("last_modified", newest).Msg("desired state updated")
+		r.notifySource(ctx, notify.SourceAlert{
+			Kind:     notify.SourceStale,
+			Resolved: true,
+			Message:  "desired state updated",
+			Revision: r.desiredRevision,
+		})
+	}
+	r.sourceStale = stale
+}
+
+func (r *Runner) notifySource(ctx context.Context, alert notify.SourceAlert) {
+	if r.notifier == nil {
+		return
+	}
+	if err := r.notifier.NotifySource(ctx, r.stackKey(), alert); err != nil {
+		r.logger.Error().Err(err).Msg("failed to send source alert")
+	}
+}
+
+// withRevision annotates a log event with the desired state's source commit, if known.
+func (r *Runner) withRevision(event *zerolog.Event) *zerolog.Event {
+	if r.desiredRevision == nil {
+		return event
+	}
+	event = event.Str("desired_commit", r.desiredRevision.Commit)
+	if r.desiredRevision.Author != "" {
+		event = event.Str("desired_author", r.desiredRevision.Author)
+	}
+	return event
 }
 
 func (r *Runner) withStateLock(fn func() error) error {
@@ -11,6 +11,8 @@ import (
 
 	"github.com/nholik/swarm-sentinel/internal/compose"
 	"github.com/nholik/swarm-sentinel/internal/health"
+	"github.com/nholik/swarm-sentinel/internal/healthcheck"
+	"github.com/nholik/swarm-sentinel/internal/notify"
 	"github.com/nholik/swarm-sentinel/internal/state"
 	"github.com/nholik/swarm-sentinel/internal/swarm"
 	"github.com/nholik/swarm-sentinel/internal/transition"
@@ -230,7 +232,8 @@ func (m *memoryStateStore) Save(_ context.Context, st state.State) error {
 }
 
 type recordingNotifier struct {
-	calls [][]transition.ServiceTransition
+	calls  [][]transition.ServiceTransition
+	alerts []notify.SourceAlert
 }
 
 func (n *recordingNotifier) Notify(_ context.Context, _ string, transitions []transition.ServiceTransition) error {
@@ -238,6 +241,11 @@ func (n *recordingNotifier) Notify(_ context.Context, _ string, transitions []tr
 	return nil
 }
 
+func (n *recordingNotifier) NotifySource(_ context.Context, _ string, alert notify.SourceAlert) error {
+	n.alerts = append(n.alerts, alert)
+	return nil
+}
+
 func TestRunner_AlertStabilizationDelaysNotification(t *testing.T) {
 	store := &memoryStateStore{}
 	notifier := &recordingNotifier{}
@@ -454,3 +462,535 @@ services:
 		t.Fatalf("expected stack name to be passed on every call")
 	}
 }
+
+func TestRunner_RunOnce_AttachesRevisionToTransitions(t *testing.T) {
+	validCompose := []byte(`
+services:
+  web:
+    image: nginx:latest
+`)
+	revision := &compose.Revision{Commit: "0123456789abcdef", Author: "Deploy Bot <deploy@example.com>"}
+	fetcher := &recordingFetcher{
+		results: []compose.FetchResult{
+			{Body: validCompose, ETag: revision.Commit, Revision: revision},
+		},
+	}
+	swarmClient := &fakeSwarmClient{
+		state: &swarm.ActualState{
+			Services: map[string]swarm.ActualService{},
+		},
+	}
+	notifier := &recordingNotifier{}
+
+	r := New(zerolog.Nop(), time.Second,
+		WithComposeFetcher(fetcher),
+		WithSwarmClient(swarmClient),
+		WithStateStore(&memoryStateStore{}, &sync.Mutex{}),
+		WithNotifier(notifier),
+	)
+
+	if err := r.RunOnce(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(notifier.calls) != 1 || len(notifier.calls[0]) != 1 {
+		t.Fatalf("expected one transition, got %+v", notifier.calls)
+	}
+	got := notifier.calls[0][0].DesiredRevision
+	if got == nil || got.Commit != revision.Commit || got.Author != revision.Author {
+		t.Fatalf("expected revision %+v on transition, got %+v", revision, got)
+	}
+}
+
+func TestRunner_RunOnce_KeepsRevisionOfLastParsedCompose(t *testing.T) {
+	good := &compose.Revision{Commit: "aaaaaaaaaaaa"}
+	broken := &compose.Revision{Commit: "bbbbbbbbbbbb"}
+	results := []compose.FetchResult{
+		{Body: []byte("services:\n  web:\n    image: nginx:1.27\n"), ETag: good.Commit, Revision: good},
+		{Body: []byte("services: [\n"), ETag: broken.Commit, Revision: broken},
+		{NotModified: true, ETag: broken.Commit, Revision: broken},
+	}
+	cycle := 0
+	swarmClient := &fakeSwarmClient{
+		state: &swarm.ActualState{Services: map[string]swarm.ActualService{}},
+	}
+	notifier := &recordingNotifier{}
+	r := New(zerolog.Nop(), time.Second,
+		WithComposeFetcher(fetchFunc(func(string) (compose.FetchResult, error) {
+			result := results[cycle]
+			cycle++
+			return result, nil
+		})),
+		WithSwarmClient(swarmClient),
+		WithStateStore(&memoryStateStore{}, &sync.Mutex{}),
+		WithNotifier(notifier),
+	)
+
+	if err := r.RunOnce(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	swarmClient.state = &swarm.ActualState{Services: map[string]swarm.ActualService{
+		"web": {Name: "web", Image: "nginx:1.27", Mode: "replicated", DesiredReplicas: 1, RunningReplicas: 1},
+	}}
+	_ = r.RunOnce(context.Background())
+	_ = r.RunOnce(context.Background())
+
+	if len(notifier.calls) != 2 {
+		t.Fatalf("expected a transition before and after the broken commit, got %+v", notifier.calls)
+	}
+	for _, call := range notifier.calls {
+		if got := call[0].DesiredRevision; got == nil || got.Commit != good.Commit {
+			t.Fatalf("expected transitions to carry the last parsed commit, got %+v", got)
+		}
+	}
+	if len(notifier.alerts) != 1 || notifier.alerts[0].Revision == nil || notifier.alerts[0].Revision.Commit != good.Commit {
+		t.Fatalf("expected parse alert to carry the desired state's commit, got %+v", notifier.alerts)
+	}
+}
+
+type scriptedVerifier struct {
+	errs  []error
+	calls int
+}
+
+func (v *scriptedVerifier) Verify(context.Context, []byte) error {
+	if v.calls >= len(v.errs) {
+		v.calls++
+		return nil
+	}
+	err := v.errs[v.calls]
+	v.calls++
+	return err
+}
+
+func TestRunner_RunOnce_RejectsUnverifiedCompose(t *testing.T) {
+	trusted := []byte("services:\n  web:\n    image: nginx:1.27\n")
+	tampered := []byte("services:\n  web:\n    image: evil:latest\n")
+	fetcher := &recordingFetcher{
+		results: []compose.FetchResult{
+			{Body: trusted, ETag: "etag-1"},
+			{Body: tampered, ETag: "etag-2"},
+			{Body: tampered, ETag: "etag-2"},
+			{Body: tampered, ETag: "etag-2"},
+		},
+	}
+	sigErr := &compose.SignatureError{URL: "https://example.com/compose.yml.sig", Err: errors.New("signature does not match any trusted key")}
+	verifier := &scriptedVerifier{errs: []error{nil, sigErr, sigErr, nil}}
+	notifier := &recordingNotifier{}
+	swarmClient := &fakeSwarmClient{
+		state: &swarm.ActualState{Services: map[string]swarm.ActualService{
+			"web": {Name: "web", Image: "nginx:1.27", Mode: "replicated", DesiredReplicas: 1, RunningReplicas: 1},
+		}},
+	}
+
+	r := New(zerolog.Nop(), time.Second,
+		WithComposeFetcher(fetcher),
+		WithComposeVerifier(verifier),
+		WithSwarmClient(swarmClient),
+		WithStateStore(&memoryStateStore{}, &sync.Mutex{}),
+		WithNotifier(notifier),
+	)
+
+	if err := r.RunOnce(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	swarmClient.state.Services["web"] = swarm.ActualService{Name: "web", Image: "nginx:1.26", Mode: "replicated", DesiredReplicas: 1, RunningReplicas: 1}
+	for i := 0; i < 2; i++ {
+		if err := r.RunOnce(context.Background()); err != nil {
+			t.Fatalf("expected evaluation to continue against trusted compose, got %v", err)
+		}
+	}
+	if len(notifier.calls) != 1 || notifier.calls[0][0].CurrentStatus != health.StatusDegraded {
+		t.Fatalf("expected drift to be detected while compose is rejected, got %+v", notifier.calls)
+	}
+	if got := r.lastDesiredState.Services["web"].Image; got != "nginx:1.27" {
+		t.Fatalf("expected last trusted desired state to be kept, got image %q", got)
+	}
+	if swarmClient.calls != 3 {
+		t.Fatalf("expected actual state to be collected every cycle, got %d", swarmClient.calls)
+	}
+	if fetcher.calls[2] != "etag-1" {
+		t.Fatalf("expected rejected compose etag not to be recorded, got %q", fetcher.calls[2])
+	}
+	if len(notifier.alerts) != 1 || notifier.alerts[0].Kind != notify.SourceSignatureInvalid || notifier.alerts[0].Resolved {
+		t.Fatalf("expected a single signature alert, got %+v", notifier.alerts)
+	}
+
+	swarmClient.state.Services["web"] = swarm.ActualService{Name: "web", Image: "evil:latest", Mode: "replicated", DesiredReplicas: 1, RunningReplicas: 1}
+	if err := r.RunOnce(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := r.lastDesiredState.Services["web"].Image; got != "evil:latest" {
+		t.Fatalf("expected verified compose to be adopted, got image %q", got)
+	}
+	if len(notifier.alerts) != 2 || !notifier.alerts[1].Resolved {
+		t.Fatalf("expected resolved alert, got %+v", notifier.alerts)
+	}
+}
+
+func TestRunner_RunOnce_ReformattedComposeKeepsSemanticFingerprint(t *testing.T) {
+	fetcher := &recordingFetcher{
+		results: []compose.FetchResult{
+			{Body: []byte("services:\n  web:\n    image: nginx:1.27\n"), ETag: "etag-1"},
+			{Body: []byte("# re-rendered\nservices:\n  web: {image: 'nginx:1.27'}\n"), ETag: "etag-2"},
+		},
+	}
+	r := New(zerolog.Nop(), time.Second, WithComposeFetcher(fetcher))
+
+	if err := r.RunOnce(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	semantic, raw := r.composeHash, r.composeRawHash
+
+	if err := r.RunOnce(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.composeHash != semantic {
+		t.Fatalf("expected semantic fingerprint to be unchanged, got %s (was %s)", r.composeHash, semantic)
+	}
+	if r.composeRawHash == raw {
+		t.Fatal("expected raw fingerprint to track the new bytes")
+	}
+}
+
+func TestRunner_RunOnce_MergesComposeSources(t *testing.T) {
+	base := &recordingFetcher{
+		results: []compose.FetchResult{
+			{Body: []byte("services:\n  web:\n    image: nginx:1.27\n  worker:\n    image: busybox:1.36\n"), ETag: "base-1"},
+			{NotModified: true, ETag: "base-1"},
+		},
+	}
+	overlay := &recordingFetcher{
+		results: []compose.FetchResult{
+			{Body: []byte("services:\n  web:\n    image: nginx:1.27-alpine\n"), ETag: "prod-1"},
+			{Body: []byte("services:\n  web:\n    image: nginx:1.28-alpine\n"), ETag: "prod-2"},
+		},
+	}
+	r := New(zerolog.Nop(), time.Second, WithComposeSources(
+		ComposeSource{Name: "base.yml", Fetcher: base},
+		ComposeSource{Name: "prod.yml", Fetcher: overlay},
+	))
+
+	if err := r.RunOnce(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := r.lastDesiredState.Services["web"].Image; got != "nginx:1.27-alpine" {
+		t.Fatalf("expected overlay image, got %q", got)
+	}
+	if _, ok := r.lastDesiredState.Services["worker"]; !ok {
+		t.Fatalf("expected worker from base file")
+	}
+	first := r.composeRawHash
+
+	if err := r.RunOnce(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if base.calls[1] != "base-1" || overlay.calls[1] != "prod-1" {
+		t.Fatalf("expected per-source etags, got base=%v overlay=%v", base.calls, overlay.calls)
+	}
+	if r.composeRawHash == first {
+		t.Fatal("expected combined fingerprint to change with the overlay")
+	}
+	if got := r.lastDesiredState.Services["web"].Image; got != "nginx:1.28-alpine" {
+		t.Fatalf("expected updated overlay image, got %q", got)
+	}
+}
+
+func TestRunner_RunOnce_InterpolatesFromEnvFile(t *testing.T) {
+	fetcher := &recordingFetcher{
+		results: []compose.FetchResult{
+			{Body: []byte("services:\n  web:\n    image: nginx:${TAG}\n"), ETag: "compose-1"},
+			{NotModified: true, ETag: "compose-1"},
+			{NotModified: true, ETag: "compose-1"},
+		},
+	}
+	envFetcher := &recordingFetcher{
+		results: []compose.FetchResult{
+			{Body: []byte("TAG=1.27\n"), ETag: "env-1"},
+			{Body: []byte("TAG=1.28\n"), ETag: "env-2"},
+			{Body: []byte("OTHER=1\n"), ETag: "env-3"},
+		},
+	}
+	r := New(zerolog.Nop(), time.Second, WithComposeFetcher(fetcher), WithComposeEnv(envFetcher))
+
+	if err := r.RunOnce(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := r.lastDesiredState.Services["web"].Image; got != "nginx:1.27" {
+		t.Fatalf("unexpected image: %q", got)
+	}
+
+	if err := r.RunOnce(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := r.lastDesiredState.Services["web"].Image; got != "nginx:1.28" {
+		t.Fatalf("expected env change to re-parse compose, got %q", got)
+	}
+
+	if err := r.RunOnce(context.Background()); err != nil {
+		t.Fatalf("expected cycle to continue against last desired state, got %v", err)
+	}
+	var ierr *compose.InterpolationError
+	if !errors.As(r.parseErr, &ierr) || ierr.Service != "web" || ierr.Variable != "TAG" {
+		t.Fatalf("expected unresolved TAG error, got %v", r.parseErr)
+	}
+	if got := r.lastDesiredState.Services["web"].Image; got != "nginx:1.28" {
+		t.Fatalf("expected last desired state to be kept, got %q", got)
+	}
+}
+
+type fetchFunc func(previousETag string) (compose.FetchResult, error)
+
+func (f fetchFunc) Fetch(_ context.Context, previousETag string) (compose.FetchResult, error) {
+	return f(previousETag)
+}
+
+func TestRunner_RunOnce_FallsBackToCachedDesiredState(t *testing.T) {
+	cache := state.NewFileDesiredCache(t.TempDir(), zerolog.Nop())
+	body := []byte("services:\n  web:\n    image: nginx:1.27\n")
+	swarmClient := &fakeSwarmClient{
+		state: &swarm.ActualState{Services: map[string]swarm.ActualService{}},
+	}
+
+	first := New(zerolog.Nop(), time.Second,
+		WithComposeFetcher(fetchFunc(func(string) (compose.FetchResult, error) {
+			return compose.FetchResult{Body: body, ETag: "etag-1"}, nil
+		})),
+		WithStackName("prod"),
+		WithDesiredStateCache(cache),
+	)
+	if err := first.RunOnce(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Restart while the compose source is down.
+	online := false
+	var etags []string
+	tracker := healthcheck.NewTracker()
+	notifier := &recordingNotifier{}
+	restarted := New(zerolog.Nop(), time.Second,
+		WithComposeFetcher(fetchFunc(func(previousETag string) (compose.FetchResult, error) {
+			etags = append(etags, previousETag)
+			if !online {
+				return compose.FetchResult{}, errors.New("connection refused")
+			}
+			return compose.FetchResult{NotModified: true, ETag: previousETag}, nil
+		})),
+		WithSwarmClient(swarmClient),
+		WithStackName("prod"),
+		WithStateStore(&memoryStateStore{}, &sync.Mutex{}),
+		WithNotifier(notifier),
+		WithCycleTracker(tracker),
+		WithDesiredStateCache(cache),
+	)
+
+	if err := restarted.RunOnce(context.Background()); err != nil {
+		t.Fatalf("expected cycle to succeed against cached state, got %v", err)
+	}
+	if len(notifier.calls) != 1 || notifier.calls[0][0].Name != "web" {
+		t.Fatalf("expected health to be evaluated against cached state, got %+v", notifier.calls)
+	}
+	if _, ok := tracker.Snapshot().CachedDesiredState["prod"]; !ok {
+		t.Fatalf("expected health snapshot to flag cached desired state, got %+v", tracker.Snapshot())
+	}
+
+	online = true
+	if err := restarted.RunOnce(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if etags[1] != "etag-1" {
+		t.Fatalf("expected cached etag to be reused, got %v", etags)
+	}
+	if len(tracker.Snapshot().CachedDesiredState) != 0 {
+		t.Fatalf("expected cached flag to clear once the source responds, got %+v", tracker.Snapshot())
+	}
+}
+
+func TestRunner_RunOnce_AlertsOnUnreachableSource(t *testing.T) {
+	body := []byte("services:\n  web:\n    image: nginx:1.27\n")
+	var fetchErr error
+	notifier := &recordingNotifier{}
+	r := New(zerolog.Nop(), time.Second,
+		WithComposeFetcher(fetchFunc(func(string) (compose.FetchResult, error) {
+			if fetchErr != nil {
+				return compose.FetchResult{}, fetchErr
+			}
+			return compose.FetchResult{Body: body, ETag: "etag-1"}, nil
+		})),
+		WithNotifier(notifier),
+		WithSourceAlerts(2, 0),
+	)
+
+	if err := r.RunOnce(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fetchErr = errors.New("connection refused")
+	for i := 0; i < 3; i++ {
+		_ = r.RunOnce(context.Background())
+	}
+	if len(notifier.alerts) != 1 || notifier.alerts[0].Kind != notify.SourceUnreachable || notifier.alerts[0].Resolved {
+		t.Fatalf("expected a single unreachable alert, got %+v", notifier.alerts)
+	}
+	if !strings.Contains(notifier.alerts[0].Error, "connection refused") {
+		t.Fatalf("expected fetch error in alert, got %q", notifier.alerts[0].Error)
+	}
+
+	fetchErr = nil
+	if err := r.RunOnce(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(notifier.alerts) != 2 || !notifier.alerts[1].Resolved {
+		t.Fatalf("expected recovery alert, got %+v", notifier.alerts)
+	}
+}
+
+func TestRunner_RunOnce_CountsPersistentParseFailures(t *testing.T) {
+	notifier := &recordingNotifier{}
+	r := New(zerolog.Nop(), time.Second,
+		WithComposeFetcher(fetchFunc(func(previousETag string) (compose.FetchResult, error) {
+			if previousETag == "etag-1" {
+				return compose.FetchResult{NotModified: true, ETag: "etag-1"}, nil
+			}
+			return compose.FetchResult{Body: []byte("services: [\n"), ETag: "etag-1"}, nil
+		})),
+		WithNotifier(notifier),
+		WithSourceAlerts(2, 0),
+	)
+
+	_ = r.RunOnce(context.Background())
+	_ = r.RunOnce(context.Background())
+	if len(notifier.alerts) != 2 || notifier.alerts[0].Kind != notify.SourceParseError || notifier.alerts[1].Kind != notify.SourceUnreachable {
+		t.Fatalf("expected unchanged broken compose to keep counting, got %+v", notifier.alerts)
+	}
+}
+
+func TestRunner_RunOnce_AlertsOnParseError(t *testing.T) {
+	notifier := &recordingNotifier{}
+	tracker := healthcheck.NewTracker()
+	bodies := []string{
+		"services:\n  web:\n    image: nginx:1.27\n    ports: 80\n",
+		"services:\n  web:\n    image: nginx:1.27\n    ports: 80\n",
+		"services:\n  web:\n    image: nginx:1.27\n",
+	}
+	cycle := 0
+	r := New(zerolog.Nop(), time.Second,
+		WithStackName("prod"),
+		WithComposeFetcher(fetchFunc(func(string) (compose.FetchResult, error) {
+			body := bodies[cycle]
+			cycle++
+			return compose.FetchResult{Body: []byte(body)}, nil
+		})),
+		WithNotifier(notifier),
+		WithCycleTracker(tracker),
+	)
+
+	if err := r.RunOnce(context.Background()); err == nil {
+		t.Fatal("expected parse error")
+	}
+	_ = r.RunOnce(context.Background())
+	if len(notifier.alerts) != 1 || notifier.alerts[0].Kind != notify.SourceParseError {
+		t.Fatalf("expected a single parse error alert, got %+v", notifier.alerts)
+	}
+	perr := notifier.alerts[0].ParseError
+	if perr == nil || perr.File != "compose.yml" || perr.Line != 4 || perr.Service != "web" || perr.Field != "ports" {
+		t.Fatalf("unexpected parse error location: %+v", perr)
+	}
+	if _, ok := tracker.Snapshot().ParseErrors["prod"]; !ok {
+		t.Fatalf("expected parse error on health snapshot")
+	}
+
+	if err := r.RunOnce(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(notifier.alerts) != 2 || notifier.alerts[1].Kind != notify.SourceParseError || !notifier.alerts[1].Resolved {
+		t.Fatalf("expected parse error to resolve, got %+v", notifier.alerts)
+	}
+	if len(tracker.Snapshot().ParseErrors) != 0 {
+		t.Fatalf("expected parse error to clear from health snapshot")
+	}
+}
+
+func TestRunner_RunOnce_UnchangedBrokenComposeKeepsFailing(t *testing.T) {
+	notifier := &recordingNotifier{}
+	r := New(zerolog.Nop(), time.Second,
+		WithComposeFetcher(fetchFunc(func(string) (compose.FetchResult, error) {
+			return compose.FetchResult{Body: []byte("services: [\n")}, nil
+		})),
+		WithNotifier(notifier),
+	)
+
+	for i := 0; i < 2; i++ {
+		err := r.RunOnce(context.Background())
+		var runtimeErr *RuntimeError
+		if !errors.As(err, &runtimeErr) || runtimeErr.Op != "compose parse" {
+			t.Fatalf("cycle %d: expected compose parse error, got %v", i+1, err)
+		}
+	}
+	if len(notifier.alerts) != 1 {
+		t.Fatalf("expected unchanged broken compose to alert once, got %+v", notifier.alerts)
+	}
+}
+
+func TestRunner_RunOnce_EvaluatesLastParsedComposeAfterParseError(t *testing.T) {
+	bodies := []string{
+		"services:\n  web:\n    image: nginx:1.27\n",
+		"services: [\n",
+		"services: [\n",
+	}
+	cycle := 0
+	swarmClient := &fakeSwarmClient{
+		state: &swarm.ActualState{Services: map[string]swarm.ActualService{}},
+	}
+	notifier := &recordingNotifier{}
+	r := New(zerolog.Nop(), time.Second,
+		WithComposeFetcher(fetchFunc(func(string) (compose.FetchResult, error) {
+			body := bodies[cycle]
+			cycle++
+			return compose.FetchResult{Body: []byte(body)}, nil
+		})),
+		WithSwarmClient(swarmClient),
+		WithStateStore(&memoryStateStore{}, &sync.Mutex{}),
+		WithNotifier(notifier),
+	)
+
+	for i := range bodies {
+		if err := r.RunOnce(context.Background()); err != nil {
+			t.Fatalf("cycle %d: unexpected error: %v", i+1, err)
+		}
+		if r.parseErr == nil && i > 0 {
+			t.Fatalf("cycle %d: expected parse error to persist", i+1)
+		}
+	}
+	if swarmClient.calls != 3 {
+		t.Fatalf("expected every cycle to evaluate, got %d", swarmClient.calls)
+	}
+}
+
+func TestRunner_CheckSourceAge(t *testing.T) {
+	notifier := &recordingNotifier{}
+	r := New(zerolog.Nop(), time.Second,
+		WithComposeFetcher(fetchFunc(func(string) (compose.FetchResult, error) {
+			return compose.FetchResult{}, nil
+		})),
+		WithNotifier(notifier),
+		WithSourceAlerts(0, time.Hour),
+	)
+	modified := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	r.sources[0].lastModified = modified
+
+	r.checkSourceAge(context.Background(), modified.Add(30*time.Minute))
+	if len(notifier.alerts) != 0 {
+		t.Fatalf("expected no alert within max age, got %+v", notifier.alerts)
+	}
+	r.checkSourceAge(context.Background(), modified.Add(2*time.Hour))
+	r.checkSourceAge(context.Background(), modified.Add(3*time.Hour))
+	if len(notifier.alerts) != 1 || notifier.alerts[0].Kind != notify.SourceStale || notifier.alerts[0].Resolved {
+		t.Fatalf("expected a single stale alert, got %+v", notifier.alerts)
+	}
+
+	r.sources[0].lastModified = modified.Add(150 * time.Minute)
+	r.checkSourceAge(context.Background(), modified.Add(3*time.Hour))
+	if len(notifier.alerts) != 2 || !notifier.alerts[1].Resolved {
+		t.Fatalf("expected stale alert to resolve, got %+v", notifier.alerts)
+	}
+}
@@ -0,0 +1,107 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nholik/swarm-sentinel/internal/compose"
+	"github.com/rs/zerolog"
+)
+
+// CachedDesiredState is the last compose content that parsed successfully for
+// a stack. It lets the sentinel keep evaluating health when the compose source
+// is unreachable at startup.
+type CachedDesiredState struct {
+	Files          []CachedComposeFile `json:"files"`
+	Env            []byte              `json:"env,omitempty"`
+	Fingerprint    string              `json:"fingerprint"`
+	RawFingerprint string              `json:"raw_fingerprint"`
+	Revision       *compose.Revision   `json:"revision,omitempty"`
+	CachedAt       time.Time           `json:"cached_at"`
+}
+
+// CachedComposeFile is a single cached compose source with the ETag it was
+// served with.
+type CachedComposeFile struct {
+	Name string `json:"name"`
+	ETag string `json:"etag,omitempty"`
+	Body []byte `json:"body"`
+}
+
+// DesiredCache persists the last known good desired state per stack.
+type DesiredCache interface {
+	// LoadDesired returns nil without error when nothing is cached for stack.
+	LoadDesired(ctx context.Context, stack string) (*CachedDesiredState, error)
+	SaveDesired(ctx context.Context, stack string, cached CachedDesiredState) error
+}
+
+// FileDesiredCache stores one JSON file per stack in a directory.
+type FileDesiredCache struct {
+	dir    string
+	logger zerolog.Logger
+}
+
+// NewFileDesiredCache returns a cache rooted at dir.
+func NewFileDesiredCache(dir string, logger zerolog.Logger) *FileDesiredCache {
+	return &FileDesiredCache{
+		dir:    dir,
+		logger: logger,
+	}
+}
+
+// DesiredCacheDir returns the cache directory kept next to a state file.
+func DesiredCacheDir(statePath string) string {
+	return filepath.Join(filepath.Dir(statePath), "desired")
+}
+
+// LoadDesired reads the cached desired state for stack. Corrupt entries are
+// ignored with a warning, as with the state file.
+func (c *FileDesiredCache) LoadDesired(ctx context.Context, stack string) (*CachedDesiredState, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	path := c.path(stack)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var cached CachedDesiredState
+	if err := json.Unmarshal(data, &cached); err != nil || len(cached.Files) == 0 {
+		c.logger.Warn().Str("path", path).Err(err).Msg("desired state cache corrupt, ignoring")
+		return nil, nil
+	}
+	return &cached, nil
+}
+
+// SaveDesired writes the cached desired state for stack atomically.
+func (c *FileDesiredCache) SaveDesired(ctx context.Context, stack string, cached CachedDesiredState) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return writeJSONAtomic(c.path(stack), cached)
+}
+
+func (c *FileDesiredCache) path(stack string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, stack)
+	if name == "" || strings.Trim(name, ".") == "" {
+		name = "_"
+	}
+	return filepath.Join(c.dir, name+".json")
+}
@@ -0,0 +1,70 @@
+package state
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nholik/swarm-sentinel/internal/compose"
+	"github.com/rs/zerolog"
+)
+
+func TestFileDesiredCache_RoundTrip(t *testing.T) {
+	dir := DesiredCacheDir(filepath.Join(t.TempDir(), "state.json"))
+	cache := NewFileDesiredCache(dir, zerolog.Nop())
+
+	missing, err := cache.LoadDesired(context.Background(), "prod")
+	if err != nil || missing != nil {
+		t.Fatalf("expected empty cache, got %+v, %v", missing, err)
+	}
+
+	cached := CachedDesiredState{
+		Files: []CachedComposeFile{
+			{Name: "base.yml", ETag: "etag-1", Body: []byte("services:\n  web:\n    image: nginx:1.27\n")},
+		},
+		Env:            []byte("TAG=1.27\n"),
+		Fingerprint:    "semantic",
+		RawFingerprint: "raw",
+		Revision:       &compose.Revision{Commit: "abc123"},
+		CachedAt:       time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	if err := cache.SaveDesired(context.Background(), "prod/../blue", cached); err != nil {
+		t.Fatalf("save cache: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read cache dir: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "prod_.._blue.json" {
+		t.Fatalf("expected a single sanitized cache file, got %v", entries)
+	}
+
+	loaded, err := cache.LoadDesired(context.Background(), "prod/../blue")
+	if err != nil {
+		t.Fatalf("load cache: %v", err)
+	}
+	if loaded == nil || string(loaded.Files[0].Body) != string(cached.Files[0].Body) || loaded.Files[0].ETag != "etag-1" {
+		t.Fatalf("unexpected cached files: %+v", loaded)
+	}
+	if string(loaded.Env) != "TAG=1.27\n" || loaded.Revision == nil || loaded.Revision.Commit != "abc123" {
+		t.Fatalf("unexpected cached metadata: %+v", loaded)
+	}
+	if !loaded.CachedAt.Equal(cached.CachedAt) {
+		t.Fatalf("unexpected cached_at: %v", loaded.CachedAt)
+	}
+}
+
+func TestFileDesiredCache_CorruptIgnored(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "prod.json"), []byte("{not json"), 0o600); err != nil {
+		t.Fatalf("write cache: %v", err)
+	}
+
+	loaded, err := NewFileDesiredCache(dir, zerolog.Nop()).LoadDesired(context.Background(), "prod")
+	if err != nil || loaded != nil {
+		t.Fatalf("expected corrupt cache to be ignored, got %+v, %v", loaded, err)
+	}
+}
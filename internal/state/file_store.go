@@ -79,7 +79,13 @@ func (s *FileStore) Save(ctx context.Context, state State) error {
 		state.Stacks = map[string]StackSnapshot{}
 	}
 
-	dir := filepath.Dir(s.path)
+	return writeJSONAtomic(s.path, state)
+}
+
+// writeJSONAtomic encodes v to a temp file in the target directory and
+// renames it into place so readers never observe a partial write.
+func writeJSONAtomic(path string, v any) error {
+	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return err
 	}
@@ -102,7 +108,7 @@ func (s *FileStore) Save(ctx context.Context, state State) error {
 	}
 
 	encoder := json.NewEncoder(tempFile)
-	if err := encoder.Encode(state); err != nil {
+	if err := encoder.Encode(v); err != nil {
 		_ = tempFile.Close()
 		cleanup()
 		return err
@@ -117,7 +123,7 @@ func (s *FileStore) Save(ctx context.Context, state State) error {
 		return err
 	}
 
-	if err := os.Rename(tempFile.Name(), s.path); err != nil {
+	if err := os.Rename(tempFile.Name(), path); err != nil {
 		cleanup()
 		return err
 	}
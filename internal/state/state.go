@@ -8,10 +8,13 @@ import (
 )
 
 // StackSnapshot captures the persisted health state for a stack.
+// DesiredFingerprint is the semantic compose fingerprint used for change
+// detection; DesiredRawFingerprint hashes the exact bytes that were fetched.
 type StackSnapshot struct {
-	DesiredFingerprint string                          `json:"desired_fingerprint"`
-	Services           map[string]health.ServiceHealth `json:"services"`
-	EvaluatedAt        time.Time                       `json:"evaluated_at"`
+	DesiredFingerprint    string                          `json:"desired_fingerprint"`
+	DesiredRawFingerprint string                          `json:"desired_raw_fingerprint,omitempty"`
+	Services              map[string]health.ServiceHealth `json:"services"`
+	EvaluatedAt           time.Time                       `json:"evaluated_at"`
 }
 
 // CurrentStateVersion is the current schema version for the state file.
@@ -1,10 +1,15 @@
 package swarm
 
 import (
+	"context"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
 	swarmtypes "github.com/docker/docker/api/types/swarm"
+	"github.com/nholik/swarm-sentinel/internal/compose"
 )
 
 func TestNormalizeServiceName(t *testing.T) {
@@ -101,6 +106,7 @@ func TestSummarizeTasks_RunningOnly(t *testing.T) {
 			Status: swarmtypes.TaskStatus{State: swarmtypes.TaskStateRunning},
 			Spec: swarmtypes.TaskSpec{
 				ContainerSpec: &swarmtypes.ContainerSpec{
+					Image: "app:v1@sha256:bbb",
 					Configs: []*swarmtypes.ConfigReference{
 						{ConfigName: "app_config_v2"},
 						nil,
@@ -115,6 +121,7 @@ func TestSummarizeTasks_RunningOnly(t *testing.T) {
 			Status: swarmtypes.TaskStatus{State: swarmtypes.TaskStateRunning},
 			Spec: swarmtypes.TaskSpec{
 				ContainerSpec: &swarmtypes.ContainerSpec{
+					Image: "app:v1@sha256:aaa",
 					Configs: []*swarmtypes.ConfigReference{
 						{ConfigName: "app_config_v2"},
 						{ConfigName: "other_config_v1"},
@@ -130,6 +137,7 @@ func TestSummarizeTasks_RunningOnly(t *testing.T) {
 			Status: swarmtypes.TaskStatus{State: swarmtypes.TaskStateFailed},
 			Spec: swarmtypes.TaskSpec{
 				ContainerSpec: &swarmtypes.ContainerSpec{
+					Image: "app:v1@sha256:ccc",
 					Configs: []*swarmtypes.ConfigReference{
 						{ConfigName: "ignored_config"},
 					},
@@ -141,14 +149,333 @@ func TestSummarizeTasks_RunningOnly(t *testing.T) {
 		},
 	}
 
-	running, configs, secrets := summarizeTasks(tasks)
-	if running != 2 {
-		t.Fatalf("expected 2 running tasks, got %d", running)
+	summary := summarizeTasks(tasks, nil)
+	if summary.running != 2 {
+		t.Fatalf("expected 2 running tasks, got %d", summary.running)
 	}
-	if !reflect.DeepEqual(configs, []string{"app_config_v2", "other_config_v1"}) {
-		t.Fatalf("unexpected configs: %+v", configs)
+	if summary.failed != 1 {
+		t.Fatalf("expected 1 failed task, got %d", summary.failed)
 	}
-	if !reflect.DeepEqual(secrets, []string{"api_secret_v3", "db_secret_v1"}) {
-		t.Fatalf("unexpected secrets: %+v", secrets)
+	if !reflect.DeepEqual(summary.configs, []string{"app_config_v2", "other_config_v1"}) {
+		t.Fatalf("unexpected configs: %+v", summary.configs)
+	}
+	if !reflect.DeepEqual(summary.secrets, []string{"api_secret_v3", "db_secret_v1"}) {
+		t.Fatalf("unexpected secrets: %+v", summary.secrets)
+	}
+	if !reflect.DeepEqual(summary.digests, []string{"sha256:aaa", "sha256:bbb"}) {
+		t.Fatalf("unexpected digests of running tasks: %+v", summary.digests)
+	}
+}
+
+func TestSummarizeTasks_JobIteration(t *testing.T) {
+	t.Parallel()
+
+	task := func(state swarmtypes.TaskState, iteration uint64) swarmtypes.Task {
+		return swarmtypes.Task{
+			Status:       swarmtypes.TaskStatus{State: state},
+			JobIteration: &swarmtypes.Version{Index: iteration},
+		}
+	}
+	tasks := []swarmtypes.Task{
+		task(swarmtypes.TaskStateComplete, 7),
+		task(swarmtypes.TaskStateComplete, 7),
+		task(swarmtypes.TaskStateFailed, 7),
+		task(swarmtypes.TaskStateRejected, 7),
+		task(swarmtypes.TaskStateRunning, 7),
+		task(swarmtypes.TaskStateComplete, 3),
+		task(swarmtypes.TaskStateFailed, 3),
+	}
+
+	tasks[0].Spec.ContainerSpec = &swarmtypes.ContainerSpec{
+		Configs: []*swarmtypes.ConfigReference{{ConfigName: "cfg"}},
+		Secrets: []*swarmtypes.SecretReference{{SecretName: "db_pass"}},
+	}
+	tasks[5].Spec.ContainerSpec = &swarmtypes.ContainerSpec{
+		Configs: []*swarmtypes.ConfigReference{{ConfigName: "old_cfg"}},
+	}
+
+	summary := summarizeTasks(tasks, &swarmtypes.Version{Index: 7})
+	if summary.completed != 2 || summary.failed != 2 || summary.running != 1 {
+		t.Fatalf("unexpected summary of the current run: %+v", summary)
+	}
+	if !reflect.DeepEqual(summary.configs, []string{"cfg"}) || !reflect.DeepEqual(summary.secrets, []string{"db_pass"}) {
+		t.Fatalf("expected configs and secrets of completed tasks in the current run, got %+v %+v", summary.configs, summary.secrets)
+	}
+
+	summary = summarizeTasks(tasks, nil)
+	if summary.completed != 3 || summary.failed != 3 {
+		t.Fatalf("unexpected summary across runs: %+v", summary)
+	}
+}
+
+func TestReplicatedJob(t *testing.T) {
+	t.Parallel()
+
+	if maxConcurrent, total := replicatedJob(swarmtypes.Service{}); maxConcurrent != 0 || total != 0 {
+		t.Fatalf("expected zeros for a non-job service, got %d %d", maxConcurrent, total)
+	}
+
+	var service swarmtypes.Service
+	service.Spec.Mode.ReplicatedJob = &swarmtypes.ReplicatedJob{}
+	if maxConcurrent, total := replicatedJob(service); maxConcurrent != 1 || total != 1 {
+		t.Fatalf("expected Swarm defaults of 1, got %d %d", maxConcurrent, total)
+	}
+
+	maxConcurrent, total := uint64(2), uint64(5)
+	service.Spec.Mode.ReplicatedJob = &swarmtypes.ReplicatedJob{MaxConcurrent: &maxConcurrent, TotalCompletions: &total}
+	if gotMax, gotTotal := replicatedJob(service); gotMax != 2 || gotTotal != 5 {
+		t.Fatalf("unexpected job settings: %d %d", gotMax, gotTotal)
+	}
+}
+
+func TestSummarizeEndpoint(t *testing.T) {
+	t.Parallel()
+
+	ports, mode := summarizeEndpoint(nil)
+	if ports != nil || mode != "vip" {
+		t.Fatalf("expected vip default without endpoint spec, got %v %q", ports, mode)
+	}
+
+	ports, mode = summarizeEndpoint(&swarmtypes.EndpointSpec{
+		Mode: swarmtypes.ResolutionModeDNSRR,
+		Ports: []swarmtypes.PortConfig{
+			{TargetPort: 443, PublishedPort: 8443, Protocol: swarmtypes.PortConfigProtocolTCP, PublishMode: swarmtypes.PortConfigPublishModeHost},
+			{TargetPort: 80, PublishedPort: 8080},
+		},
+	})
+	if mode != "dnsrr" {
+		t.Fatalf("expected dnsrr, got %q", mode)
+	}
+	want := []PortMapping{
+		{Target: 80, Published: 8080, Protocol: "tcp", Mode: "ingress"},
+		{Target: 443, Published: 8443, Protocol: "tcp", Mode: "host"},
+	}
+	if !reflect.DeepEqual(ports, want) {
+		t.Fatalf("unexpected ports: %+v", ports)
+	}
+}
+
+func TestSummarizeEnv(t *testing.T) {
+	t.Parallel()
+
+	env := summarizeEnv([]string{"LOG_LEVEL=debug", "TOKEN=a=b", "BARE", "LOG_LEVEL=info"})
+	if len(env) != 3 {
+		t.Fatalf("expected 3 variables, got %+v", env)
+	}
+	if env["LOG_LEVEL"] != compose.HashEnvValue("info") || env["TOKEN"] != compose.HashEnvValue("a=b") || env["BARE"] != "" {
+		t.Fatalf("unexpected digests: %+v", env)
+	}
+	for _, digest := range env {
+		if digest == "info" || digest == "a=b" {
+			t.Fatalf("expected values to be hashed, got %+v", env)
+		}
+	}
+}
+
+func TestUserLabels(t *testing.T) {
+	t.Parallel()
+
+	labels := userLabels(map[string]string{
+		"com.docker.stack.namespace": "prod",
+		"com.docker.stack.image":     "nginx:1.27",
+		"traefik.enable":             "true",
+	})
+	if !reflect.DeepEqual(labels, map[string]string{"traefik.enable": "true"}) {
+		t.Fatalf("unexpected labels: %+v", labels)
+	}
+	if userLabels(map[string]string{"com.docker.stack.namespace": "prod"}) != nil {
+		t.Fatal("expected nil when only stack labels are present")
+	}
+}
+
+func TestSummarizeResources(t *testing.T) {
+	t.Parallel()
+
+	if got := summarizeResources(nil); !reflect.DeepEqual(got, Resources{}) {
+		t.Fatalf("expected empty resources, got %+v", got)
+	}
+
+	got := summarizeResources(&swarmtypes.ResourceRequirements{
+		Limits: &swarmtypes.Limit{NanoCPUs: 500000000, MemoryBytes: 1 << 29, Pids: 100},
+		Reservations: &swarmtypes.Resources{
+			MemoryBytes: 1 << 27,
+			GenericResources: []swarmtypes.GenericResource{
+				{DiscreteResourceSpec: &swarmtypes.DiscreteGenericResource{Kind: "gpu", Value: 2}},
+				{NamedResourceSpec: &swarmtypes.NamedGenericResource{Kind: "ssd", Value: "fast"}},
+			},
+		},
+	})
+	want := Resources{
+		Limits:       ResourceSpec{NanoCPUs: 500000000, MemoryBytes: 1 << 29, Pids: 100},
+		Reservations: ResourceSpec{MemoryBytes: 1 << 27, Generic: map[string]int64{"gpu": 2}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected resources: %+v", got)
+	}
+}
+
+func TestSummarizeResources_MatchesCompose(t *testing.T) {
+	t.Parallel()
+
+	desired, err := compose.ParseDesiredState(context.Background(), []byte(`
+services:
+  web:
+    image: nginx:1.27
+    deploy:
+      resources:
+        limits:
+          cpus: "0.5"
+          memory: 512M
+          pids: 100
+        reservations:
+          cpus: "0.25"
+          memory: 128M
+          generic_resources:
+            - discrete_resource_spec:
+                kind: gpu
+                value: 2
+`))
+	if err != nil {
+		t.Fatalf("parse compose: %v", err)
+	}
+
+	// The requirements `docker stack deploy` creates for the compose above.
+	actual := summarizeResources(&swarmtypes.ResourceRequirements{
+		Limits: &swarmtypes.Limit{NanoCPUs: 500000000, MemoryBytes: 512 << 20, Pids: 100},
+		Reservations: &swarmtypes.Resources{
+			NanoCPUs:    250000000,
+			MemoryBytes: 128 << 20,
+			GenericResources: []swarmtypes.GenericResource{
+				{DiscreteResourceSpec: &swarmtypes.DiscreteGenericResource{Kind: "gpu", Value: 2}},
+			},
+		},
+	})
+
+	want := desired.Services["web"].Resources
+	if !reflect.DeepEqual(compose.ResourceSpec(actual.Limits), want.Limits) {
+		t.Fatalf("limits differ: compose %+v swarm %+v", want.Limits, actual.Limits)
+	}
+	if !reflect.DeepEqual(compose.ResourceSpec(actual.Reservations), want.Reservations) {
+		t.Fatalf("reservations differ: compose %+v swarm %+v", want.Reservations, actual.Reservations)
+	}
+}
+
+func TestSummarizePlacement(t *testing.T) {
+	t.Parallel()
+
+	got := summarizePlacement(&swarmtypes.Placement{
+		Constraints: []string{"node.role==worker", "node.labels.pool==web"},
+		Preferences: []swarmtypes.PlacementPreference{
+			{Spread: &swarmtypes.SpreadOver{SpreadDescriptor: "node.labels.zone"}},
+			{},
+		},
+		MaxReplicas: 2,
+	})
+	want := Placement{
+		Constraints:        []string{"node.labels.pool==web", "node.role==worker"},
+		Preferences:        []string{"node.labels.zone"},
+		MaxReplicasPerNode: 2,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected placement: %+v", got)
+	}
+}
+
+func TestSummarizeNetworks(t *testing.T) {
+	t.Parallel()
+
+	got := summarizeNetworks([]swarmtypes.NetworkAttachmentConfig{
+		{Target: "net2", Aliases: []string{"www", "web"}},
+		{Target: "shared"},
+		{Target: "net1"},
+	}, map[string]string{"net1": "prod_backend", "net2": "prod_frontend"})
+	want := []NetworkAttachment{
+		{Name: "prod_backend"},
+		{Name: "prod_frontend", Aliases: []string{"web", "www"}},
+		{Name: "shared"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected networks: %+v", got)
+	}
+}
+
+func TestSummarizePolicies(t *testing.T) {
+	t.Parallel()
+
+	defaults := UpdatePolicy{Parallelism: 1, FailureAction: "pause", Monitor: 5 * time.Second, Order: "stop-first"}
+	if got := summarizeUpdatePolicy(nil); got != defaults {
+		t.Fatalf("unexpected default update policy: %+v", got)
+	}
+	got := summarizeUpdatePolicy(&swarmtypes.UpdateConfig{Parallelism: 0, FailureAction: "continue"})
+	want := UpdatePolicy{Parallelism: 0, FailureAction: "continue", Monitor: 5 * time.Second, Order: "stop-first"}
+	if got != want {
+		t.Fatalf("unexpected update policy: %+v", got)
+	}
+
+	attempts := uint64(3)
+	restart := summarizeRestartPolicy(&swarmtypes.RestartPolicy{
+		Condition:   swarmtypes.RestartPolicyConditionOnFailure,
+		MaxAttempts: &attempts,
+	})
+	if want := (RestartPolicy{Condition: "on-failure", Delay: 5 * time.Second, MaxAttempts: 3}); restart != want {
+		t.Fatalf("unexpected restart policy: %+v", restart)
+	}
+}
+
+func TestSummarizeHealthcheck(t *testing.T) {
+	t.Parallel()
+
+	if got := summarizeHealthcheck(nil); got != nil {
+		t.Fatalf("expected nil healthcheck, got %+v", got)
+	}
+	got := summarizeHealthcheck(&container.HealthConfig{
+		Test:     []string{"CMD", "curl", "-f", "http://localhost/"},
+		Interval: time.Minute,
+		Retries:  3,
+	})
+	want := &Healthcheck{
+		Test:     []string{"CMD", "curl", "-f", "http://localhost/"},
+		Interval: time.Minute,
+		Retries:  3,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected healthcheck: %+v", got)
+	}
+}
+
+func TestSummarizeMounts(t *testing.T) {
+	t.Parallel()
+
+	got := summarizeMounts([]mount.Mount{
+		{Type: mount.TypeVolume, Source: "prod_data", Target: "/data", VolumeOptions: &mount.VolumeOptions{
+			DriverConfig: &mount.Driver{Name: "local", Options: map[string]string{"type": "nfs"}},
+		}},
+		{Type: mount.TypeBind, Source: "/srv/conf", Target: "/etc/conf", ReadOnly: true},
+	})
+	want := []Mount{
+		{Type: "volume", Source: "prod_data", Target: "/data", Driver: "local", DriverOptions: map[string]string{"type": "nfs"}},
+		{Type: "bind", Source: "/srv/conf", Target: "/etc/conf", ReadOnly: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected mounts: %+v", got)
+	}
+}
+
+func TestSummarizeRuntime(t *testing.T) {
+	t.Parallel()
+
+	grace := 30 * time.Second
+	got := summarizeRuntime(&swarmtypes.ContainerSpec{
+		Command:         []string{"/entrypoint.sh"},
+		Args:            []string{"serve", "--debug"},
+		User:            "app",
+		Dir:             "/srv",
+		StopGracePeriod: &grace,
+	})
+	if !reflect.DeepEqual(got.command, []string{"serve", "--debug"}) || !reflect.DeepEqual(got.entrypoint, []string{"/entrypoint.sh"}) {
+		t.Fatalf("expected args as command and command as entrypoint, got %+v", got)
+	}
+	if got.user != "app" || got.workingDir != "/srv" || got.stopGracePeriod != grace {
+		t.Fatalf("unexpected runtime settings: %+v", got)
 	}
 }
@@ -36,6 +36,9 @@ type dockerAPI interface {
 	// TaskList returns tasks matching the given options.
 	TaskList(ctx context.Context, options dockertypes.TaskListOptions) ([]swarmtypes.Task, error)
 
+	// NetworkList returns networks matching the given options.
+	NetworkList(ctx context.Context, options dockertypes.NetworkListOptions) ([]dockertypes.NetworkResource, error)
+
 	// Close releases resources associated with the client.
 	Close() error
 }
@@ -56,6 +59,7 @@ type dockerClientInterface interface {
 	Ping(ctx context.Context) (dockertypes.Ping, error)
 	ServiceList(ctx context.Context, options dockertypes.ServiceListOptions) ([]swarmtypes.Service, error)
 	TaskList(ctx context.Context, options dockertypes.TaskListOptions) ([]swarmtypes.Task, error)
+	NetworkList(ctx context.Context, options dockertypes.NetworkListOptions) ([]dockertypes.NetworkResource, error)
 	Close() error
 }
 
@@ -71,6 +75,10 @@ func (a *dockerClientAdapter) TaskList(ctx context.Context, options dockertypes.
 	return a.client.TaskList(ctx, options)
 }
 
+func (a *dockerClientAdapter) NetworkList(ctx context.Context, options dockertypes.NetworkListOptions) ([]dockertypes.NetworkResource, error) {
+	return a.client.NetworkList(ctx, options)
+}
+
 func (a *dockerClientAdapter) Close() error {
 	return a.client.Close()
 }
@@ -17,6 +17,7 @@ type mockDockerAPI struct {
 	pingFn        func(ctx context.Context) (dockertypes.Ping, error)
 	serviceListFn func(ctx context.Context, options dockertypes.ServiceListOptions) ([]swarmtypes.Service, error)
 	taskListFn    func(ctx context.Context, options dockertypes.TaskListOptions) ([]swarmtypes.Task, error)
+	networkListFn func(ctx context.Context, options dockertypes.NetworkListOptions) ([]dockertypes.NetworkResource, error)
 	closeFn       func() error
 }
 
@@ -41,6 +42,13 @@ func (m *mockDockerAPI) TaskList(ctx context.Context, options dockertypes.TaskLi
 	return nil, nil
 }
 
+func (m *mockDockerAPI) NetworkList(ctx context.Context, options dockertypes.NetworkListOptions) ([]dockertypes.NetworkResource, error) {
+	if m.networkListFn != nil {
+		return m.networkListFn(ctx, options)
+	}
+	return nil, nil
+}
+
 func (m *mockDockerAPI) Close() error {
 	if m.closeFn != nil {
 		return m.closeFn()
@@ -341,6 +349,53 @@ func TestDockerClient_GetActualState_GlobalMode(t *testing.T) {
 	}
 }
 
+func TestDockerClient_GetActualState_ResolvesNetworkNames(t *testing.T) {
+	t.Parallel()
+
+	var networkCalls int32
+	mock := &mockDockerAPI{
+		serviceListFn: func(ctx context.Context, options dockertypes.ServiceListOptions) ([]swarmtypes.Service, error) {
+			return []swarmtypes.Service{
+				{
+					ID: "svc1",
+					Spec: swarmtypes.ServiceSpec{
+						Annotations: swarmtypes.Annotations{Name: "prod_web"},
+						TaskTemplate: swarmtypes.TaskSpec{
+							Networks: []swarmtypes.NetworkAttachmentConfig{
+								{Target: "abc123", Aliases: []string{"web"}},
+							},
+						},
+					},
+				},
+				{
+					ID:   "svc2",
+					Spec: swarmtypes.ServiceSpec{Annotations: swarmtypes.Annotations{Name: "prod_worker"}},
+				},
+			}, nil
+		},
+		networkListFn: func(ctx context.Context, options dockertypes.NetworkListOptions) ([]dockertypes.NetworkResource, error) {
+			atomic.AddInt32(&networkCalls, 1)
+			return []dockertypes.NetworkResource{{ID: "abc123", Name: "prod_default"}}, nil
+		},
+	}
+
+	client := &DockerClient{api: mock, timeout: 5 * time.Second, logger: zerolog.Nop()}
+	state, err := client.GetActualState(context.Background(), "prod")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := atomic.LoadInt32(&networkCalls); got != 1 {
+		t.Fatalf("expected a single network lookup, got %d", got)
+	}
+	web := state.Services["web"].Networks
+	if len(web) != 1 || web[0].Name != "prod_default" || len(web[0].Aliases) != 1 || web[0].Aliases[0] != "web" {
+		t.Fatalf("unexpected web networks: %+v", web)
+	}
+	if got := state.Services["worker"].Networks; got != nil {
+		t.Fatalf("expected no networks for worker, got %+v", got)
+	}
+}
+
 func TestDockerClient_GetActualState_ServiceListError(t *testing.T) {
 	t.Parallel()
 
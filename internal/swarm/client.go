@@ -1,6 +1,9 @@
 package swarm
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // ActualService represents a service's runtime state from Swarm.
 //
@@ -15,7 +18,8 @@ import "context"
 //   - For global mode: compare ActualService.DesiredReplicas with ActualService.RunningReplicas
 //     (DesiredService.Replicas will be 0, indicating "use Swarm's dynamic count")
 //
-// Use swarm.NormalizeImage() when comparing Image fields to strip digest suffixes.
+// Use swarm.NormalizeImage() when comparing Image fields to strip digest suffixes,
+// and swarm.ImageDigest() to compare the digests themselves.
 type ActualService struct {
 	Name            string   // Service name (stack prefix stripped if applicable)
 	Image           string   // Image reference (may include @sha256:... digest)
@@ -25,6 +29,140 @@ type ActualService struct {
 	Configs         []string // Sorted list of config names from running tasks
 	Secrets         []string // Sorted list of secret names from running tasks
 	UpdateState     string   // UpdateStatus.State when present (e.g., updating, rollback_started)
+	// TaskDigests lists the image digests running tasks use, sorted. Tasks
+	// keep the digest resolved when they were created, so more than one
+	// outside an update means replicas run different image content.
+	TaskDigests []string
+	// CompletedTasks and FailedTasks count the tasks of a job's current run
+	// that completed or failed; they stay 0 for other modes, which only count
+	// running tasks. Configs, Secrets and TaskDigests of a job come from
+	// every task of its current run. MaxConcurrent and TotalCompletions are
+	// set for replicated jobs.
+	CompletedTasks   int
+	FailedTasks      int
+	MaxConcurrent    int
+	TotalCompletions int
+	// Ports lists the ports published in the service spec, sorted by target
+	// port and protocol.
+	Ports []PortMapping
+	// EndpointMode is "vip" or "dnsrr".
+	EndpointMode string
+	// Environment maps variable names from the service spec to value
+	// digests from compose.HashEnvValue. A bare name has an empty
+	// digest.
+	Environment map[string]string
+	// Labels holds the service labels and ContainerLabels the container
+	// labels from the spec, both without the com.docker.stack.* labels that
+	// `docker stack deploy` adds.
+	Labels          map[string]string
+	ContainerLabels map[string]string
+	// Resources holds TaskTemplate.Resources from the spec.
+	Resources Resources
+	// Placement holds TaskTemplate.Placement from the spec.
+	Placement Placement
+	// Networks lists TaskTemplate.Networks by network name, sorted by name.
+	// A network that no longer exists is reported by its ID.
+	Networks []NetworkAttachment
+	// UpdateConfig, RollbackConfig and RestartPolicy hold the spec's
+	// policies with the daemon's defaults filled in for unset fields.
+	UpdateConfig   UpdatePolicy
+	RollbackConfig UpdatePolicy
+	RestartPolicy  RestartPolicy
+	// Healthcheck holds ContainerSpec.Healthcheck, nil when the spec has none.
+	Healthcheck *Healthcheck
+	// Mounts lists ContainerSpec.Mounts sorted by target.
+	Mounts []Mount
+	// Command holds ContainerSpec.Args and Entrypoint ContainerSpec.Command,
+	// matching the compose keys they are deployed from. StopGracePeriod is 0
+	// when unset.
+	Command         []string
+	Entrypoint      []string
+	User            string
+	WorkingDir      string
+	Hostname        string
+	StopSignal      string
+	StopGracePeriod time.Duration
+}
+
+// Mount is a mount from the container spec. Source is empty for anonymous
+// volumes and tmpfs mounts; Driver and DriverOptions come from the volume
+// driver config.
+type Mount struct {
+	Type          string
+	Source        string
+	Target        string
+	ReadOnly      bool
+	Driver        string
+	DriverOptions map[string]string
+}
+
+// Healthcheck holds a service's healthcheck. Test is ["NONE"] when it is
+// disabled; empty tests, zero durations and zero retries are inherited from
+// the image.
+type Healthcheck struct {
+	Test        []string
+	Interval    time.Duration
+	Timeout     time.Duration
+	StartPeriod time.Duration
+	Retries     uint64
+}
+
+// UpdatePolicy holds a service's update or rollback configuration.
+type UpdatePolicy struct {
+	Parallelism     uint64
+	Delay           time.Duration
+	FailureAction   string
+	Monitor         time.Duration
+	MaxFailureRatio float32
+	Order           string
+}
+
+// RestartPolicy holds a service's restart policy. MaxAttempts and Window are
+// 0 when unlimited.
+type RestartPolicy struct {
+	Condition   string
+	Delay       time.Duration
+	MaxAttempts uint64
+	Window      time.Duration
+}
+
+// NetworkAttachment is a network a service joins, with its sorted aliases.
+type NetworkAttachment struct {
+	Name    string
+	Aliases []string
+}
+
+// Placement holds a service's scheduling rules. Constraints are sorted;
+// Preferences are spread descriptors in priority order. MaxReplicasPerNode is
+// 0 when unlimited.
+type Placement struct {
+	Constraints        []string
+	Preferences        []string
+	MaxReplicasPerNode uint64
+}
+
+// Resources holds a service's resource limits and reservations.
+type Resources struct {
+	Limits       ResourceSpec
+	Reservations ResourceSpec
+}
+
+// ResourceSpec is one side of a service's resources. Zero values are unset.
+// Generic maps discrete generic resource kinds to their counts.
+type ResourceSpec struct {
+	NanoCPUs    int64
+	MemoryBytes int64
+	Pids        int64
+	Generic     map[string]int64
+}
+
+// PortMapping is a port published by a service. Published is 0 when the spec
+// leaves the published port to Swarm; Mode is "ingress" or "host".
+type PortMapping struct {
+	Target    uint32
+	Published uint32
+	Protocol  string
+	Mode      string
 }
 
 // ActualState represents the complete runtime state of the stack.
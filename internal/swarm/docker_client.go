@@ -11,11 +11,14 @@ import (
 	"time"
 
 	dockertypes "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
 	swarmtypes "github.com/docker/docker/api/types/swarm"
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/errdefs"
 	"github.com/docker/go-connections/tlsconfig"
+	"github.com/nholik/swarm-sentinel/internal/compose"
 	"github.com/rs/zerolog"
 )
 
@@ -187,11 +190,17 @@ func (c *DockerClient) GetActualState(ctx context.Context, stackName string) (*A
 		Services: make(map[string]ActualService, len(services)),
 	}
 
+	networkNames, err := c.networkNames(ctx, services)
+	if err != nil {
+		return nil, err
+	}
+
 	for _, service := range services {
 		actualService, err := c.collectServiceState(ctx, service, stackName)
 		if err != nil {
 			return nil, err
 		}
+		actualService.Networks = summarizeNetworks(service.Spec.TaskTemplate.Networks, networkNames)
 		state.Services[actualService.Name] = actualService
 	}
 
@@ -202,8 +211,17 @@ func (c *DockerClient) collectServiceState(ctx context.Context, service swarmtyp
 	name := normalizeServiceName(service.Spec.Name, stackName)
 	mode, desired := serviceModeAndReplicas(service)
 	image := ""
-	if service.Spec.TaskTemplate.ContainerSpec != nil {
-		image = service.Spec.TaskTemplate.ContainerSpec.Image
+	var environment, containerLabels map[string]string
+	var healthcheck *Healthcheck
+	var mounts []Mount
+	var runtime containerRuntime
+	if spec := service.Spec.TaskTemplate.ContainerSpec; spec != nil {
+		image = spec.Image
+		environment = summarizeEnv(spec.Env)
+		containerLabels = userLabels(spec.Labels)
+		healthcheck = summarizeHealthcheck(spec.Healthcheck)
+		mounts = summarizeMounts(spec.Mounts)
+		runtime = summarizeRuntime(spec)
 	}
 	updateState := ""
 	if service.UpdateStatus != nil {
@@ -217,20 +235,376 @@ func (c *DockerClient) collectServiceState(ctx context.Context, service swarmtyp
 		return ActualService{}, err
 	}
 
-	runningReplicas, configs, secrets := summarizeTasks(tasks)
+	tasksSummary := summarizeTasks(tasks, jobIteration(service))
+	maxConcurrent, totalCompletions := replicatedJob(service)
+	ports, endpointMode := summarizeEndpoint(service.Spec.EndpointSpec)
 
 	return ActualService{
-		Name:            name,
-		Image:           image,
-		Mode:            mode,
-		DesiredReplicas: desired,
-		RunningReplicas: runningReplicas,
-		Configs:         configs,
-		Secrets:         secrets,
-		UpdateState:     updateState,
+		Name:             name,
+		Image:            image,
+		Mode:             mode,
+		DesiredReplicas:  desired,
+		RunningReplicas:  tasksSummary.running,
+		CompletedTasks:   tasksSummary.completed,
+		FailedTasks:      tasksSummary.failed,
+		MaxConcurrent:    maxConcurrent,
+		TotalCompletions: totalCompletions,
+		Configs:          tasksSummary.configs,
+		Secrets:          tasksSummary.secrets,
+		TaskDigests:      tasksSummary.digests,
+		UpdateState:      updateState,
+		Ports:            ports,
+		EndpointMode:     endpointMode,
+		Environment:      environment,
+		Labels:           userLabels(service.Spec.Labels),
+		ContainerLabels:  containerLabels,
+		Resources:        summarizeResources(service.Spec.TaskTemplate.Resources),
+		Placement:        summarizePlacement(service.Spec.TaskTemplate.Placement),
+		UpdateConfig:     summarizeUpdatePolicy(service.Spec.UpdateConfig),
+		RollbackConfig:   summarizeUpdatePolicy(service.Spec.RollbackConfig),
+		RestartPolicy:    summarizeRestartPolicy(service.Spec.TaskTemplate.RestartPolicy),
+		Healthcheck:      healthcheck,
+		Mounts:           mounts,
+		Command:          runtime.command,
+		Entrypoint:       runtime.entrypoint,
+		User:             runtime.user,
+		WorkingDir:       runtime.workingDir,
+		Hostname:         runtime.hostname,
+		StopSignal:       runtime.stopSignal,
+		StopGracePeriod:  runtime.stopGracePeriod,
 	}, nil
 }
 
+// networkNames maps network IDs to names. Service specs reference networks by
+// ID, so the list is only fetched when some service joins a network.
+func (c *DockerClient) networkNames(ctx context.Context, services []swarmtypes.Service) (map[string]string, error) {
+	attached := false
+	for _, service := range services {
+		if len(service.Spec.TaskTemplate.Networks) > 0 {
+			attached = true
+			break
+		}
+	}
+	if !attached {
+		return nil, nil
+	}
+
+	var networks []dockertypes.NetworkResource
+	err := c.withRetry(ctx, "NetworkList", func(ctx context.Context) error {
+		var listErr error
+		networks, listErr = c.api.NetworkList(ctx, dockertypes.NetworkListOptions{})
+		return listErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string, len(networks))
+	for _, network := range networks {
+		names[network.ID] = network.Name
+	}
+	return names, nil
+}
+
+// summarizeNetworks resolves a task template's network targets to names.
+// Targets may already be names, so unknown targets are kept as they are.
+func summarizeNetworks(attachments []swarmtypes.NetworkAttachmentConfig, names map[string]string) []NetworkAttachment {
+	if len(attachments) == 0 {
+		return nil
+	}
+	result := make([]NetworkAttachment, 0, len(attachments))
+	for _, attachment := range attachments {
+		name, ok := names[attachment.Target]
+		if !ok {
+			name = attachment.Target
+		}
+		var aliases []string
+		if len(attachment.Aliases) > 0 {
+			aliases = append(aliases, attachment.Aliases...)
+			sort.Strings(aliases)
+		}
+		result = append(result, NetworkAttachment{Name: name, Aliases: aliases})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// containerRuntime holds the process settings of a container spec.
+type containerRuntime struct {
+	command         []string
+	entrypoint      []string
+	user            string
+	workingDir      string
+	hostname        string
+	stopSignal      string
+	stopGracePeriod time.Duration
+}
+
+// summarizeRuntime reads the process settings of a container spec. Swarm's
+// Command overrides the image entrypoint and Args its command.
+func summarizeRuntime(spec *swarmtypes.ContainerSpec) containerRuntime {
+	runtime := containerRuntime{
+		user:       spec.User,
+		workingDir: spec.Dir,
+		hostname:   spec.Hostname,
+		stopSignal: spec.StopSignal,
+	}
+	if len(spec.Args) > 0 {
+		runtime.command = append([]string(nil), spec.Args...)
+	}
+	if len(spec.Command) > 0 {
+		runtime.entrypoint = append([]string(nil), spec.Command...)
+	}
+	if spec.StopGracePeriod != nil {
+		runtime.stopGracePeriod = *spec.StopGracePeriod
+	}
+	return runtime
+}
+
+// summarizeMounts reads the container spec's mounts.
+func summarizeMounts(specs []mount.Mount) []Mount {
+	if len(specs) == 0 {
+		return nil
+	}
+	mounts := make([]Mount, 0, len(specs))
+	for _, spec := range specs {
+		item := Mount{
+			Type:     string(spec.Type),
+			Source:   spec.Source,
+			Target:   spec.Target,
+			ReadOnly: spec.ReadOnly,
+		}
+		if spec.VolumeOptions != nil && spec.VolumeOptions.DriverConfig != nil {
+			item.Driver = spec.VolumeOptions.DriverConfig.Name
+			if len(spec.VolumeOptions.DriverConfig.Options) > 0 {
+				item.DriverOptions = make(map[string]string, len(spec.VolumeOptions.DriverConfig.Options))
+				for key, value := range spec.VolumeOptions.DriverConfig.Options {
+					item.DriverOptions[key] = value
+				}
+			}
+		}
+		mounts = append(mounts, item)
+	}
+	sort.Slice(mounts, func(i, j int) bool {
+		return mounts[i].Target < mounts[j].Target
+	})
+	return mounts
+}
+
+// summarizeHealthcheck copies the container spec's healthcheck.
+func summarizeHealthcheck(config *container.HealthConfig) *Healthcheck {
+	if config == nil {
+		return nil
+	}
+	healthcheck := &Healthcheck{
+		Interval:    config.Interval,
+		Timeout:     config.Timeout,
+		StartPeriod: config.StartPeriod,
+	}
+	if len(config.Test) > 0 {
+		healthcheck.Test = append([]string(nil), config.Test...)
+	}
+	if config.Retries > 0 {
+		healthcheck.Retries = uint64(config.Retries)
+	}
+	return healthcheck
+}
+
+// Daemon defaults for policy fields a service spec leaves unset.
+const (
+	defaultUpdateParallelism = 1
+	defaultUpdateFailure     = "pause"
+	defaultUpdateMonitor     = 5 * time.Second
+	defaultUpdateOrder       = "stop-first"
+	defaultRestartCondition  = string(swarmtypes.RestartPolicyConditionAny)
+	defaultRestartDelay      = 5 * time.Second
+)
+
+// summarizeUpdatePolicy reads an update or rollback config. A spec without
+// one gets the daemon's defaults; a zero Parallelism in a set config is kept,
+// since it means every task is updated at once.
+func summarizeUpdatePolicy(config *swarmtypes.UpdateConfig) UpdatePolicy {
+	policy := UpdatePolicy{
+		Parallelism:   defaultUpdateParallelism,
+		FailureAction: defaultUpdateFailure,
+		Monitor:       defaultUpdateMonitor,
+		Order:         defaultUpdateOrder,
+	}
+	if config == nil {
+		return policy
+	}
+	policy.Parallelism = config.Parallelism
+	policy.Delay = config.Delay
+	if config.FailureAction != "" {
+		policy.FailureAction = config.FailureAction
+	}
+	if config.Monitor != 0 {
+		policy.Monitor = config.Monitor
+	}
+	policy.MaxFailureRatio = config.MaxFailureRatio
+	if config.Order != "" {
+		policy.Order = config.Order
+	}
+	return policy
+}
+
+// summarizeRestartPolicy reads the task template's restart policy.
+func summarizeRestartPolicy(config *swarmtypes.RestartPolicy) RestartPolicy {
+	policy := RestartPolicy{
+		Condition: defaultRestartCondition,
+		Delay:     defaultRestartDelay,
+	}
+	if config == nil {
+		return policy
+	}
+	if config.Condition != "" {
+		policy.Condition = string(config.Condition)
+	}
+	if config.Delay != nil {
+		policy.Delay = *config.Delay
+	}
+	if config.MaxAttempts != nil {
+		policy.MaxAttempts = *config.MaxAttempts
+	}
+	if config.Window != nil {
+		policy.Window = *config.Window
+	}
+	return policy
+}
+
+// summarizePlacement reads scheduling rules from the task template.
+func summarizePlacement(spec *swarmtypes.Placement) Placement {
+	if spec == nil {
+		return Placement{}
+	}
+	placement := Placement{MaxReplicasPerNode: spec.MaxReplicas}
+	if len(spec.Constraints) > 0 {
+		placement.Constraints = append([]string(nil), spec.Constraints...)
+		sort.Strings(placement.Constraints)
+	}
+	for _, preference := range spec.Preferences {
+		if preference.Spread == nil {
+			continue
+		}
+		placement.Preferences = append(placement.Preferences, preference.Spread.SpreadDescriptor)
+	}
+	return placement
+}
+
+// summarizeResources reads limits and reservations from the task template.
+// Swarm only supports pids as a limit and generic resources as a reservation.
+func summarizeResources(requirements *swarmtypes.ResourceRequirements) Resources {
+	var resources Resources
+	if requirements == nil {
+		return resources
+	}
+	if limits := requirements.Limits; limits != nil {
+		resources.Limits = ResourceSpec{
+			NanoCPUs:    limits.NanoCPUs,
+			MemoryBytes: limits.MemoryBytes,
+			Pids:        limits.Pids,
+		}
+	}
+	if reservations := requirements.Reservations; reservations != nil {
+		resources.Reservations = ResourceSpec{
+			NanoCPUs:    reservations.NanoCPUs,
+			MemoryBytes: reservations.MemoryBytes,
+		}
+		for _, generic := range reservations.GenericResources {
+			if generic.DiscreteResourceSpec == nil {
+				continue
+			}
+			if resources.Reservations.Generic == nil {
+				resources.Reservations.Generic = map[string]int64{}
+			}
+			resources.Reservations.Generic[generic.DiscreteResourceSpec.Kind] = generic.DiscreteResourceSpec.Value
+		}
+	}
+	return resources
+}
+
+// stackLabelPrefix marks labels `docker stack deploy` manages itself.
+const stackLabelPrefix = "com.docker.stack."
+
+// userLabels copies labels, dropping the ones stack deploy adds.
+func userLabels(labels map[string]string) map[string]string {
+	var result map[string]string
+	for key, value := range labels {
+		if strings.HasPrefix(key, stackLabelPrefix) {
+			continue
+		}
+		if result == nil {
+			result = make(map[string]string, len(labels))
+		}
+		result[key] = value
+	}
+	return result
+}
+
+// summarizeEnv hashes KEY=VALUE entries from a container spec with
+// compose.HashEnvValue, so values are never held in plain text and compare
+// equal to the desired state's. Later duplicates win, as they do in the
+// container.
+func summarizeEnv(env []string) map[string]string {
+	if len(env) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(env))
+	for _, entry := range env {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			result[name] = ""
+			continue
+		}
+		result[name] = compose.HashEnvValue(value)
+	}
+	return result
+}
+
+// summarizeEndpoint reads published ports and the endpoint mode from the
+// service spec, filling in the defaults Swarm applies when they are omitted.
+func summarizeEndpoint(spec *swarmtypes.EndpointSpec) ([]PortMapping, string) {
+	endpointMode := string(swarmtypes.ResolutionModeVIP)
+	if spec == nil {
+		return nil, endpointMode
+	}
+	if spec.Mode != "" {
+		endpointMode = string(spec.Mode)
+	}
+	if len(spec.Ports) == 0 {
+		return nil, endpointMode
+	}
+
+	ports := make([]PortMapping, 0, len(spec.Ports))
+	for _, port := range spec.Ports {
+		mapping := PortMapping{
+			Target:    port.TargetPort,
+			Published: port.PublishedPort,
+			Protocol:  string(port.Protocol),
+			Mode:      string(port.PublishMode),
+		}
+		if mapping.Protocol == "" {
+			mapping.Protocol = string(swarmtypes.PortConfigProtocolTCP)
+		}
+		if mapping.Mode == "" {
+			mapping.Mode = string(swarmtypes.PortConfigPublishModeIngress)
+		}
+		ports = append(ports, mapping)
+	}
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].Target != ports[j].Target {
+			return ports[i].Target < ports[j].Target
+		}
+		if ports[i].Protocol != ports[j].Protocol {
+			return ports[i].Protocol < ports[j].Protocol
+		}
+		return ports[i].Published < ports[j].Published
+	})
+	return ports, endpointMode
+}
+
 func normalizeServiceName(name, stackName string) string {
 	if stackName == "" {
 		return name
@@ -432,26 +806,56 @@ func serviceModeAndReplicas(service swarmtypes.Service) (string, int) {
 	return "unknown", desired
 }
 
-// summarizeTasks counts running tasks and extracts config/secret names.
+// taskSummary aggregates a service's tasks.
+type taskSummary struct {
+	running   int
+	completed int
+	failed    int
+	configs   []string
+	secrets   []string
+	digests   []string
+}
+
+// summarizeTasks counts running tasks and extracts config/secret names and the
+// image digests they run.
 // Note: During rolling updates, different tasks may have different configs/secrets
 // attached. This function aggregates all configs/secrets from running tasks,
 // which provides a complete picture but may include both old and new versions
 // during transitions.
-func summarizeTasks(tasks []swarmtypes.Task) (int, []string, []string) {
-	running := 0
+//
+// Completed and failed (or rejected) tasks are counted for jobs. When
+// iteration is set, tasks from earlier runs of the job are skipped and the
+// remaining tasks contribute configs, secrets and digests whatever their
+// state, since a finished job has no running tasks left to read them from.
+func summarizeTasks(tasks []swarmtypes.Task, iteration *swarmtypes.Version) taskSummary {
+	var summary taskSummary
 	configs := make(map[string]struct{})
 	secrets := make(map[string]struct{})
+	digests := make(map[string]struct{})
 
 	for _, task := range tasks {
-		if task.Status.State != swarmtypes.TaskStateRunning {
+		if iteration != nil && (task.JobIteration == nil || task.JobIteration.Index != iteration.Index) {
+			continue
+		}
+		switch task.Status.State {
+		case swarmtypes.TaskStateComplete:
+			summary.completed++
+		case swarmtypes.TaskStateFailed, swarmtypes.TaskStateRejected:
+			summary.failed++
+		case swarmtypes.TaskStateRunning:
+			summary.running++
+		}
+		if iteration == nil && task.Status.State != swarmtypes.TaskStateRunning {
 			continue
 		}
-		running++
 
 		spec := task.Spec.ContainerSpec
 		if spec == nil {
 			continue
 		}
+		if digest := ImageDigest(spec.Image); digest != "" {
+			digests[digest] = struct{}{}
+		}
 
 		for _, cfg := range spec.Configs {
 			if cfg == nil || cfg.ConfigName == "" {
@@ -467,7 +871,36 @@ func summarizeTasks(tasks []swarmtypes.Task) (int, []string, []string) {
 		}
 	}
 
-	return running, normalizeNames(configs), normalizeNames(secrets)
+	summary.configs = normalizeNames(configs)
+	summary.secrets = normalizeNames(secrets)
+	summary.digests = normalizeNames(digests)
+	return summary
+}
+
+// jobIteration returns the current run of a job service, or nil for other
+// services.
+func jobIteration(service swarmtypes.Service) *swarmtypes.Version {
+	if service.JobStatus == nil {
+		return nil
+	}
+	return &service.JobStatus.JobIteration
+}
+
+// replicatedJob returns the max_concurrent and total_completions of a
+// replicated job, or zeros for other services.
+func replicatedJob(service swarmtypes.Service) (int, int) {
+	job := service.Spec.Mode.ReplicatedJob
+	if job == nil {
+		return 0, 0
+	}
+	maxConcurrent, totalCompletions := 1, 1
+	if job.MaxConcurrent != nil {
+		maxConcurrent = int(*job.MaxConcurrent)
+	}
+	if job.TotalCompletions != nil {
+		totalCompletions = int(*job.TotalCompletions)
+	}
+	return maxConcurrent, totalCompletions
 }
 
 func normalizeNames(values map[string]struct{}) []string {
@@ -17,3 +17,17 @@ func NormalizeImage(image string) string {
 	}
 	return image
 }
+
+// ImageDigest returns the sha256:... digest pinned in a Docker image reference,
+// or "" when the reference is not pinned. `docker stack deploy` resolves tags
+// to the digest they point at unless --resolve-image=never is used.
+//
+// Examples:
+//   - "nginx:1.23@sha256:abc123..." → "sha256:abc123..."
+//   - "nginx:1.23" → ""
+func ImageDigest(image string) string {
+	if idx := strings.Index(image, "@sha256:"); idx != -1 {
+		return image[idx+1:]
+	}
+	return ""
+}
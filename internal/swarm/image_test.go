@@ -62,3 +62,44 @@ func TestNormalizeImage(t *testing.T) {
 		})
 	}
 }
+
+func TestImageDigest(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "image with tag and digest",
+			input: "nginx:1.23@sha256:abc123def456",
+			want:  "sha256:abc123def456",
+		},
+		{
+			name:  "digest only reference",
+			input: "registry.example.com:5000/app@sha256:0123456789abcdef",
+			want:  "sha256:0123456789abcdef",
+		},
+		{
+			name:  "image without digest",
+			input: "registry.example.com:5000/app:v1",
+			want:  "",
+		},
+		{
+			name:  "empty string",
+			input: "",
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := ImageDigest(tt.input)
+			if got != tt.want {
+				t.Errorf("ImageDigest(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package transition
 import (
 	"sort"
 
+	"github.com/nholik/swarm-sentinel/internal/compose"
 	"github.com/nholik/swarm-sentinel/internal/health"
 	"github.com/nholik/swarm-sentinel/internal/state"
 )
@@ -26,14 +27,19 @@ type ImageChange struct {
 }
 
 // ServiceTransition captures a status transition with details.
+// Job is set for job services and reports their current run.
+// DesiredRevision identifies the commit that defined the desired state, when
+// the compose source provides one.
 type ServiceTransition struct {
-	Name           string
-	PreviousStatus health.ServiceStatus
-	CurrentStatus  health.ServiceStatus
-	Reasons        []string
-	Drift          []health.DriftDetail
-	ReplicaChange  *ReplicaChange
-	ImageChange    *ImageChange
+	Name            string
+	PreviousStatus  health.ServiceStatus
+	CurrentStatus   health.ServiceStatus
+	Reasons         []string
+	Drift           []health.DriftDetail
+	ReplicaChange   *ReplicaChange
+	ImageChange     *ImageChange
+	Job             *health.JobHealth
+	DesiredRevision *compose.Revision
 }
 
 // DetectServiceTransitions compares a previous snapshot with current health and emits transitions.
@@ -72,6 +78,7 @@ func DetectServiceTransitions(prev *state.StackSnapshot, current health.StackHea
 			Drift:          append([]health.DriftDetail(nil), currentService.Drift...),
 			ReplicaChange:  buildReplicaChange(prevService, currentService, hadPrev),
 			ImageChange:    buildImageChange(prevService, currentService, hadPrev),
+			Job:            currentService.Job,
 		})
 	}
 
@@ -217,3 +217,24 @@ func TestDetectServiceTransitions_SortedOrder(t *testing.T) {
 		t.Fatalf("expected third transition to be zebra, got %s", transitions[2].Name)
 	}
 }
+
+func TestDetectServiceTransitions_JobHealth(t *testing.T) {
+	job := &health.JobHealth{State: health.JobFailed, Total: 1, Failed: 3, Attempts: 3}
+	current := health.StackHealth{
+		Status: health.StatusFailed,
+		Services: map[string]health.ServiceHealth{
+			"migrate": {
+				Name:    "migrate",
+				Status:  health.StatusFailed,
+				Reasons: []string{"job failed: 0/1 completed, 3 failed attempts"},
+				Job:     job,
+			},
+		},
+	}
+
+	transitions := DetectServiceTransitions(nil, current)
+
+	if len(transitions) != 1 || transitions[0].Job != job {
+		t.Fatalf("expected job health in transition, got %+v", transitions)
+	}
+}